@@ -0,0 +1,152 @@
+package iso8583
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthRequest(t *testing.T) {
+	data := &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F7:  NewNumeric("0701111844"),
+		F11: NewNumeric("000123"),
+		F22: NewNumeric("901"),
+		F25: NewNumeric("02"),
+		F41: NewAlphanumeric("00000321"),
+		F42: NewAlphanumeric("120000000000034"),
+		F49: NewNumeric("643"),
+	}
+	msg := NewMessage("0200", data)
+
+	req, err := msg.ToAuthRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, "4276555555555555", req.PAN.Value)
+	assert.Equal(t, "000000077700", req.Amount.Value)
+	assert.Equal(t, "000123", req.STAN.Value)
+	assert.Equal(t, "901", req.POSEntryMode.Value)
+	assert.Equal(t, "00000321", req.TerminalID.Value)
+	assert.Equal(t, "643", req.CurrencyCode.Value)
+	assert.Nil(t, req.MerchantType)
+	assert.Nil(t, req.Track2)
+
+	// the view is a copy
+	req.Amount.Value = "000000088800"
+	assert.Equal(t, "000000077700", data.F4.Value)
+
+	out := req.ToMessage()
+	assert.Equal(t, "0200", out.Mti)
+	res, err := out.Bytes()
+	assert.Nil(t, err)
+
+	parsed := NewMessage("", &AuthRequest{
+		PAN: NewLlnumeric(""), ProcessingCode: NewNumeric(""), Amount: NewNumeric(""),
+		TransmissionDateTime: NewNumeric(""), STAN: NewNumeric(""), POSEntryMode: NewNumeric(""),
+		POSConditionCode: NewNumeric(""), TerminalID: NewAlphanumeric(""),
+		MerchantID: NewAlphanumeric(""), CurrencyCode: NewNumeric(""),
+	})
+	assert.Nil(t, parsed.Load(res))
+	assert.Equal(t, "0200", parsed.Mti)
+	assert.Equal(t, "000000088800", parsed.Data.(*AuthRequest).Amount.Value)
+}
+
+func TestAuthRequestErrors(t *testing.T) {
+	data := &TestISO{
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F7:  NewNumeric("0701111844"),
+		F41: NewAlphanumeric("00000321"),
+		F49: NewNumeric("643"),
+	}
+	msg := NewMessage("0200", data)
+
+	_, err := msg.ToAuthRequest()
+	assert.EqualError(t, err, "field 11 is mandatory")
+
+	type mistyped struct {
+		F3  *Numeric      `field:"3" length:"6"`
+		F4  *Numeric      `field:"4" length:"12"`
+		F7  *Numeric      `field:"7" length:"10"`
+		F11 *Alphanumeric `field:"11" length:"6"`
+		F41 *Alphanumeric `field:"41" length:"8"`
+		F49 *Numeric      `field:"49" length:"3"`
+	}
+	msg = NewMessage("0200", &mistyped{
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F7:  NewNumeric("0701111844"),
+		F11: NewAlphanumeric("000123"),
+		F41: NewAlphanumeric("00000321"),
+		F49: NewNumeric("643"),
+	})
+	_, err = msg.ToAuthRequest()
+	assert.EqualError(t, err, "field 11: expected *iso8583.Numeric, got *iso8583.Alphanumeric")
+}
+
+func TestAuthResponse(t *testing.T) {
+	resp := &AuthResponse{
+		ProcessingCode:       NewNumeric("000000"),
+		Amount:               NewNumeric("000000077700"),
+		TransmissionDateTime: NewNumeric("0701111844"),
+		STAN:                 NewNumeric("000123"),
+		ResponseCode:         NewAlphanumeric("00"),
+		TerminalID:           NewAlphanumeric("00000321"),
+		CurrencyCode:         NewNumeric("643"),
+	}
+	msg := resp.ToMessage()
+	assert.Equal(t, "0210", msg.Mti)
+
+	view, err := msg.ToAuthResponse()
+	assert.Nil(t, err)
+	assert.Equal(t, "00", view.ResponseCode.Value)
+
+	resp.ResponseCode.Value = ""
+	_, err = msg.ToAuthResponse()
+	assert.EqualError(t, err, "field 39 is mandatory")
+}
+
+func TestNewAuthRequest(t *testing.T) {
+	msg := NewAuthRequest()
+	assert.Equal(t, "0200", msg.Mti)
+
+	req := msg.Data.(*AuthRequest)
+	assert.Equal(t, "000001", req.STAN.Value)
+	assert.Len(t, req.TransmissionDateTime.Value, 10)
+	_, err := time.Parse("0102150405", req.TransmissionDateTime.Value)
+	assert.Nil(t, err)
+	assert.Nil(t, req.PAN)
+	assert.Nil(t, req.Amount)
+
+	resp := NewAuthResponse(msg, "00", "A1B2C3")
+	assert.Equal(t, "0210", resp.Mti)
+
+	data := resp.Data.(*AuthResponse)
+	assert.Equal(t, req.TransmissionDateTime.Value, data.TransmissionDateTime.Value)
+	assert.Equal(t, "000001", data.STAN.Value)
+	assert.Equal(t, "00", data.ResponseCode.Value)
+	assert.Equal(t, "A1B2C3", data.ApprovalCode.Value)
+	assert.Nil(t, data.RRN)
+
+	// echo fields are copies
+	data.STAN.Value = "000002"
+	assert.Equal(t, "000001", req.STAN.Value)
+}
+
+func TestNewAuthResponseConverts(t *testing.T) {
+	type request struct {
+		F7  *Numeric   `field:"7" length:"10" encode:"ascii"`
+		F11 *Llnumeric `field:"11" length:"6" encode:"ascii,ascii"`
+		F37 *Llvar     `field:"37" length:"12" encode:"ascii,ascii"`
+	}
+	req := NewMessage("0200", &request{
+		F11: NewLlnumeric("000042"),
+		F37: NewLlvar([]byte("123456789012")),
+	})
+	data := NewAuthResponse(req, "00", "A1B2C3").Data.(*AuthResponse)
+	assert.Nil(t, data.TransmissionDateTime)
+	assert.Equal(t, "000042", data.STAN.Value)
+	assert.Equal(t, "123456789012", data.RRN.Value)
+}
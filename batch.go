@@ -0,0 +1,79 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+func init() {
+	registerFeature("parallel_batch_decode")
+}
+
+// DecodeBatchParallel reads framed records from r, such as a clearing file,
+// and parses them with p on workers goroutines. Records are framed with a
+// big-endian length head of frameLen (1 to 4) bytes, 2 for files written by
+// Replay, and read in order; only the parsing is spread over the workers.
+//
+// handle is called for every record with its index in r, from 0, and the
+// result of Parse. It is called concurrently and in no particular order, so
+// it must be safe for concurrent use. Parse errors are passed to handle and
+// do not stop the batch.
+//
+// It returns when all records read have been handled: at the end of r, on
+// a read error, or when ctx is done, with ctx.Err().
+func DecodeBatchParallel(ctx context.Context, r io.Reader, p *Parser, frameLen, workers int, handle func(index int, msg *Message, err error)) error {
+	if p == nil {
+		return errors.New("parser is required")
+	}
+	if frameLen < 1 || frameLen > 4 {
+		return fmt.Errorf("invalid frame head length %d", frameLen)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	type record struct {
+		index int
+		raw   []byte
+	}
+	records := make(chan record, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rec := range records {
+				msg, err := p.Parse(rec.raw)
+				handle(rec.index, msg, err)
+			}
+		}()
+	}
+
+	var err error
+	for i := 0; err == nil; i++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		var raw []byte
+		raw, err = readFrame(r, frameLen, p.MaxMessageSize)
+		if err == io.EOF {
+			err = nil
+			break
+		}
+		if err != nil {
+			err = fmt.Errorf("record %d: %w", i, err)
+			break
+		}
+		select {
+		case records <- record{i, raw}:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	close(records)
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,138 @@
+package iso8583
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func batchFile(t testing.TB, n int, bad map[int]bool) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		msg := NewMessage("0200", &TestISO{
+			F2:  NewLlnumeric("4276555555555555"),
+			F3:  NewNumeric("000000"),
+			F11: NewNumeric(fmt.Sprintf("%06d", i)),
+		})
+		if bad[i] {
+			msg.Mti = "0999"
+		}
+		if err := msg.Replay(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func batchParser(t testing.TB) *Parser {
+	p := &Parser{}
+	if err := p.Register("0200", &TestISO{}); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDecodeBatchParallel(t *testing.T) {
+	file := batchFile(t, 100, map[int]bool{7: true, 50: true})
+
+	var mu sync.Mutex
+	stans := make(map[int]string)
+	failed := make(map[int]error)
+	err := DecodeBatchParallel(context.Background(), bytes.NewReader(file), batchParser(t), frameHeadLen, 4,
+		func(i int, msg *Message, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[i] = err
+				return
+			}
+			stans[i] = msg.Data.(*TestISO).F11.Value
+		})
+	assert.Nil(t, err)
+	assert.Len(t, stans, 98)
+	for i, stan := range stans {
+		assert.Equal(t, fmt.Sprintf("%06d", i), stan)
+	}
+	assert.Len(t, failed, 2)
+	assert.EqualError(t, failed[7], "no template registered for MTI: 0999")
+	assert.NotNil(t, failed[50])
+
+	// a truncated last record
+	err = DecodeBatchParallel(context.Background(), bytes.NewReader(file[:len(file)-1]), batchParser(t), frameHeadLen, 2,
+		func(int, *Message, error) {})
+	assert.EqualError(t, err, "record 99: unexpected EOF")
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	err = DecodeBatchParallel(context.Background(), bytes.NewReader(file), batchParser(t), 5, 2,
+		func(int, *Message, error) {})
+	assert.EqualError(t, err, "invalid frame head length 5")
+}
+
+func TestDecodeBatchParallelFrameLen(t *testing.T) {
+	var file []byte
+	for i := 0; i < 3; i++ {
+		b, err := NewMessage("0200", &TestISO{
+			F2:  NewLlnumeric("4276555555555555"),
+			F3:  NewNumeric("000000"),
+			F11: NewNumeric(fmt.Sprintf("%06d", i)),
+		}).Bytes()
+		assert.Nil(t, err)
+		head := make([]byte, 4)
+		binary.BigEndian.PutUint32(head, uint32(len(b)))
+		file = append(append(file, head...), b...)
+	}
+
+	var mu sync.Mutex
+	stans := make(map[int]string)
+	err := DecodeBatchParallel(context.Background(), bytes.NewReader(file), batchParser(t), 4, 2,
+		func(i int, msg *Message, err error) {
+			assert.Nil(t, err)
+			mu.Lock()
+			defer mu.Unlock()
+			stans[i] = msg.Data.(*TestISO).F11.Value
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]string{0: "000000", 1: "000001", 2: "000002"}, stans)
+}
+
+func TestDecodeBatchParallelCancel(t *testing.T) {
+	file := batchFile(t, 100, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	handled := 0
+	err := DecodeBatchParallel(ctx, bytes.NewReader(file), batchParser(t), frameHeadLen, 2,
+		func(i int, msg *Message, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			handled++
+			if handled == 10 {
+				cancel()
+			}
+		})
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, handled >= 10 && handled < 100)
+}
+
+func benchmarkDecodeBatch(b *testing.B, workers int) {
+	file := batchFile(b, 10000, nil)
+	p := batchParser(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := DecodeBatchParallel(context.Background(), bytes.NewReader(file), p, frameHeadLen, workers,
+			func(int, *Message, error) {})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBatch1(b *testing.B) { benchmarkDecodeBatch(b, 1) }
+func BenchmarkDecodeBatch4(b *testing.B) { benchmarkDecodeBatch(b, 4) }
@@ -4,6 +4,10 @@ import (
 	"encoding/hex"
 )
 
+func init() {
+	registerFeature("rbcd")
+}
+
 func lbcd(data []byte) []byte {
 	if len(data)%2 != 0 {
 		return bcd(append(data, "0"...))
@@ -0,0 +1,229 @@
+package iso8583
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// SecondaryBitmapMode selects when a message carries the secondary bitmap
+type SecondaryBitmapMode int
+
+const (
+	// SecondaryBitmapManual emits the secondary bitmap when SecondBitmap is
+	// set, leaving out fields above 64 otherwise
+	SecondaryBitmapManual SecondaryBitmapMode = iota
+	// SecondaryBitmapAuto emits it when a field above 64 is present
+	SecondaryBitmapAuto
+	// SecondaryBitmapAlways emits it, all zero if no field above 64 is
+	// present
+	SecondaryBitmapAlways
+	// SecondaryBitmapNever never emits it; a field above 64 is an error
+	SecondaryBitmapNever
+)
+
+// Bitmap holds the primary and secondary bitmap of a message. Bit 1 is the
+// most significant bit of the first byte and flags the secondary bitmap.
+type Bitmap [16]byte
+
+// Set marks field n (1-128) as present
+func (b *Bitmap) Set(n int) {
+	if n < 1 || n > 128 {
+		return
+	}
+	b[(n-1)/8] |= 0x80 >> uint((n-1)%8)
+}
+
+// IsSet reports whether field n (1-128) is present
+func (b Bitmap) IsSet(n int) bool {
+	if n < 1 || n > 128 {
+		return false
+	}
+	return b[(n-1)/8]&(0x80>>uint((n-1)%8)) != 0
+}
+
+// Has reports whether all listed fields are present. It returns true for
+// an empty list.
+func (b Bitmap) Has(fields ...int) bool {
+	for _, n := range fields {
+		if !b.IsSet(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny reports whether at least one of the listed fields is present
+func (b Bitmap) HasAny(fields ...int) bool {
+	for _, n := range fields {
+		if b.IsSet(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fields returns the present data fields in ascending order. The bitmap
+// indicator bits 1 and 65 are not included.
+func (b Bitmap) Fields() []int {
+	var ret []int
+	for n, ok := b.NextSet(1); ok; n, ok = b.NextSet(n) {
+		if n != 65 {
+			ret = append(ret, n)
+		}
+	}
+	return ret
+}
+
+// NextSet returns the first present field after afterBit, so all fields can
+// be visited with
+//
+//	for n, ok := b.NextSet(0); ok; n, ok = b.NextSet(n) { ... }
+//
+// Empty bytes are skipped whole.
+func (b Bitmap) NextSet(afterBit int) (int, bool) {
+	if afterBit < 0 {
+		afterBit = 0
+	}
+	for i := afterBit / 8; i < len(b); i++ {
+		v := b[i]
+		if i == afterBit/8 {
+			// drop the bits up to afterBit
+			v &= 0xFF >> uint(afterBit%8)
+		}
+		if v != 0 {
+			// bit 1 is the most significant, so count from the left
+			return i*8 + bits.LeadingZeros8(v) + 1, true
+		}
+	}
+	return 0, false
+}
+
+// AsUint64 returns the primary and the secondary bitmap as big-endian
+// integers, so field n (1-64) of the primary bitmap is the bit
+// 1<<(64-n)
+func (b Bitmap) AsUint64() (primary, secondary uint64) {
+	return binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+}
+
+// FromUint64 sets the bitmap from the integers returned by AsUint64
+func (b *Bitmap) FromUint64(primary, secondary uint64) {
+	binary.BigEndian.PutUint64(b[:8], primary)
+	binary.BigEndian.PutUint64(b[8:], secondary)
+}
+
+// secondBitmap reports whether the message is encoded with the secondary
+// bitmap, as selected by SecondaryBitmap. Fields in drop are left out.
+func (m *Message) secondBitmap(fields map[int]*fieldInfo, drop map[int]bool) (bool, error) {
+	if m.SecondaryBitmap == SecondaryBitmapManual {
+		return m.SecondBitmap, nil
+	}
+	if m.SecondaryBitmap == SecondaryBitmapAlways {
+		return true, nil
+	}
+	for n := 65; n <= 128; n++ {
+		if info, ok := fields[n]; ok && !info.Field.IsEmpty() && !drop[n] {
+			if m.SecondaryBitmap == SecondaryBitmapNever {
+				return false, fmt.Errorf("field %d needs the secondary bitmap", n)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Bitmap returns the bitmap Bytes would emit for the message: every
+// non-empty field, with fields above 64 only when the secondary bitmap is
+// emitted
+func (m *Message) Bitmap() Bitmap {
+	var b Bitmap
+	fields, err := m.fields()
+	if err != nil {
+		return b
+	}
+	second, _ := m.secondBitmap(fields, nil)
+	if second {
+		b.Set(1)
+	}
+	for n, info := range fields {
+		if n == 1 || info.Field.IsEmpty() || (n > 64 && !second) {
+			continue
+		}
+		b.Set(n)
+	}
+	return b
+}
+
+// Has reports whether all listed fields are present in the message
+func (m *Message) Has(fields ...int) bool {
+	return m.Bitmap().Has(fields...)
+}
+
+// HasAny reports whether at least one of the listed fields is present in
+// the message
+func (m *Message) HasAny(fields ...int) bool {
+	return m.Bitmap().HasAny(fields...)
+}
+
+// SortedFieldNumbers returns the data fields present in the message in
+// ascending order, as given by Bitmap().Fields(). It is computed on every
+// call, since Data can be changed at any time.
+func (m *Message) SortedFieldNumbers() []int {
+	return m.Bitmap().Fields()
+}
+
+// BitmapInfo describes one of the bitmaps of a message
+type BitmapInfo struct {
+	// Index is 0 for the primary bitmap and 1 for the secondary bitmap
+	Index int
+	// Raw holds the 8 bytes of the bitmap, including the bit flagging the
+	// next bitmap
+	Raw []byte
+	// Fields are the data fields flagged by the bitmap, in ascending order.
+	// The bit flagging the next bitmap is not listed.
+	Fields []int
+}
+
+// Name returns "primary" or "secondary"
+func (i BitmapInfo) Name() string {
+	switch i.Index {
+	case 0:
+		return "primary"
+	case 1:
+		return "secondary"
+	}
+	return fmt.Sprintf("bitmap %d", i.Index+1)
+}
+
+// Bitmaps returns the bitmaps Bytes would emit for the message, in wire
+// order, as given by Bitmap: the primary bitmap, then the secondary bitmap
+// when it is emitted. They are computed from Data; the Extents returned by
+// LoadWithExtents and Parser.ParseWithExtents hold the bitmaps as they were
+// read. It returns nil when Presence replaces the bitmap.
+func (m *Message) Bitmaps() []BitmapInfo {
+	if m.Presence != nil {
+		return nil
+	}
+	b := m.Bitmap()
+	if b.IsSet(1) {
+		return readBitmaps(b[:16])
+	}
+	return readBitmaps(b[:8])
+}
+
+// readBitmaps describes the bitmaps in raw, 8 bytes each
+func readBitmaps(raw []byte) []BitmapInfo {
+	var b Bitmap
+	copy(b[:], raw)
+	fields := b.Fields()
+	ret := make([]BitmapInfo, len(raw)/8)
+	for i := range ret {
+		ret[i] = BitmapInfo{Index: i, Raw: append([]byte(nil), raw[i*8:i*8+8]...)}
+		for _, n := range fields {
+			if n > i*64 && n <= i*64+64 {
+				ret[i].Fields = append(ret[i].Fields, n)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,258 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitmapHas(t *testing.T) {
+	var b Bitmap
+	b.Set(2)
+	b.Set(3)
+	b.Set(4)
+	b.Set(70)
+
+	assert.True(t, b.Has())
+	assert.True(t, b.Has(2, 3, 4))
+	assert.True(t, b.Has(70))
+	assert.False(t, b.Has(2, 5))
+	assert.False(t, b.Has(0))
+	assert.False(t, b.Has(129))
+
+	assert.True(t, b.HasAny(5, 4))
+	assert.False(t, b.HasAny(5, 6, 7))
+	assert.False(t, b.HasAny())
+
+	assert.Equal(t, []int{2, 3, 4, 70}, b.Fields())
+	assert.Equal(t, Bitmap{0x70, 0, 0, 0, 0, 0, 0, 0, 0x04}, b)
+}
+
+func TestBitmapNextSet(t *testing.T) {
+	var b Bitmap
+	for _, n := range []int{1, 2, 8, 9, 64, 65, 127, 128} {
+		b.Set(n)
+	}
+	var visited []int
+	for n, ok := b.NextSet(0); ok; n, ok = b.NextSet(n) {
+		visited = append(visited, n)
+	}
+	assert.Equal(t, []int{1, 2, 8, 9, 64, 65, 127, 128}, visited)
+
+	n, ok := b.NextSet(9)
+	assert.True(t, ok)
+	assert.Equal(t, 64, n)
+	n, ok = b.NextSet(-5)
+	assert.True(t, ok)
+	assert.Equal(t, 1, n)
+
+	_, ok = b.NextSet(128)
+	assert.False(t, ok)
+	_, ok = Bitmap{}.NextSet(0)
+	assert.False(t, ok)
+}
+
+func TestMessageBitmap(t *testing.T) {
+	data := &TestISO{
+		F2:   NewLlnumeric("4276555555555555"),
+		F3:   NewNumeric("000000"),
+		F4:   NewNumeric("000000077700"),
+		F39:  NewAlphanumeric(""),
+		F120: NewLllnumeric("123"),
+	}
+	iso := NewMessage("0100", data)
+
+	assert.True(t, iso.Has(2, 3, 4))
+	assert.False(t, iso.Has(2, 39))
+	assert.True(t, iso.HasAny(39, 4))
+	assert.False(t, iso.HasAny(39, 120))
+
+	iso.SecondBitmap = true
+	assert.True(t, iso.Has(1, 120))
+
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	bitmap := iso.Bitmap()
+	assert.Equal(t, res[4:20], bitmap[:])
+}
+
+func TestMessageSortedFieldNumbers(t *testing.T) {
+	data := &TestISO{
+		F120: NewLllnumeric("123"),
+		F11:  NewNumeric("000001"),
+		F2:   NewLlnumeric("4276555555555555"),
+		F39:  NewAlphanumeric(""),
+	}
+	iso := NewMessage("0100", data)
+	iso.SecondBitmap = true
+
+	assert.Equal(t, []int{2, 11, 120}, iso.SortedFieldNumbers())
+	assert.Equal(t, []int{2, 11, 120}, iso.SortedFieldNumbers())
+
+	data.F39.Value = "00"
+	data.F2 = nil
+	assert.Equal(t, []int{11, 39, 120}, iso.SortedFieldNumbers())
+
+	assert.Empty(t, NewMessage("0800", &TestISO{}).SortedFieldNumbers())
+}
+
+func TestBitmapUint64(t *testing.T) {
+	var b Bitmap
+	b.Set(1)
+	b.Set(3)
+	b.Set(64)
+	b.Set(65)
+	b.Set(128)
+	primary, secondary := b.AsUint64()
+	assert.Equal(t, uint64(0xA000000000000001), primary)
+	assert.Equal(t, uint64(0x8000000000000001), secondary)
+
+	// field 3 and 64 present, field 4 absent
+	mask := uint64(1<<(64-3) | 1<<(64-64))
+	assert.Equal(t, mask, primary&mask)
+	assert.Zero(t, primary&(1<<(64-4)))
+
+	var c Bitmap
+	c.FromUint64(primary|1<<(64-4), secondary)
+	assert.Equal(t, []int{3, 4, 64, 128}, c.Fields())
+
+	c.FromUint64(b.AsUint64())
+	assert.Equal(t, b, c)
+}
+
+func TestSecondaryBitmapMode(t *testing.T) {
+	low := func() *TestISO { return &TestISO{F11: NewNumeric("000001")} }
+	high := func() *TestISO { return &TestISO{F11: NewNumeric("000001"), F120: NewLllnumeric("123")} }
+	roundTrip := func(mode SecondaryBitmapMode, data *TestISO) ([]byte, *Message) {
+		msg := NewMessage("0200", data)
+		msg.SecondaryBitmap = mode
+		b, err := msg.Bytes()
+		assert.Nil(t, err)
+		assert.Equal(t, msg.Bitmap().IsSet(1), b[4]&0x80 != 0)
+
+		p := &Parser{SecondaryBitmap: mode}
+		assert.Nil(t, p.Register("0200", &TestISO{}))
+		parsed, err := p.Parse(b)
+		assert.Nil(t, err)
+		again, err := parsed.Bytes()
+		assert.Nil(t, err)
+		assert.Equal(t, b, again)
+		return b, parsed
+	}
+
+	b, _ := roundTrip(SecondaryBitmapAuto, low())
+	assert.Len(t, b, 4+8+6)
+	b, parsed := roundTrip(SecondaryBitmapAuto, high())
+	assert.Len(t, b, 4+16+6+6)
+	assert.Equal(t, "123", parsed.Data.(*TestISO).F120.Value)
+
+	// an all zero secondary bitmap
+	b, parsed = roundTrip(SecondaryBitmapAlways, low())
+	assert.Len(t, b, 4+16+6)
+	assert.Equal(t, make([]byte, 8), b[12:20])
+	assert.True(t, parsed.SecondBitmap)
+	assert.Equal(t, []int{11}, parsed.SortedFieldNumbers())
+	roundTrip(SecondaryBitmapAlways, high())
+
+	b, _ = roundTrip(SecondaryBitmapNever, low())
+	assert.Len(t, b, 4+8+6)
+	msg := NewMessage("0200", high())
+	msg.SecondaryBitmap = SecondaryBitmapNever
+	_, err := msg.Bytes()
+	assert.EqualError(t, err, "field 120 needs the secondary bitmap")
+
+	// the default follows SecondBitmap and leaves out fields above 64
+	b, err = NewMessage("0200", high()).Bytes()
+	assert.Nil(t, err)
+	assert.Len(t, b, 4+8+6)
+}
+
+func TestMessageBitmaps(t *testing.T) {
+	// one bitmap
+	msg := NewMessage("0200", &TestISO{F2: NewLlnumeric("4276555555555555"), F11: NewNumeric("000001")})
+	assert.Equal(t, []BitmapInfo{
+		{Index: 0, Raw: []byte{0x40, 0x20, 0, 0, 0, 0, 0, 0}, Fields: []int{2, 11}},
+	}, msg.Bitmaps())
+
+	// two bitmaps, on encode and decode
+	msg = NewMessage("0200", &TestISO{F11: NewNumeric("000001"), F120: NewLllnumeric("123")})
+	msg.SecondaryBitmap = SecondaryBitmapAuto
+	expected := []BitmapInfo{
+		{Index: 0, Raw: []byte{0x80, 0x20, 0, 0, 0, 0, 0, 0}, Fields: []int{11}},
+		{Index: 1, Raw: []byte{0, 0, 0, 0, 0, 0, 0x01, 0}, Fields: []int{120}},
+	}
+	assert.Equal(t, expected, msg.Bitmaps())
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	p := &Parser{}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	parsed, err := p.Parse(b)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, parsed.Bitmaps())
+	assert.Equal(t, "primary", expected[0].Name())
+	assert.Equal(t, "secondary", expected[1].Name())
+
+	// bit 65 flags a further bitmap: it is in Raw but not in Fields
+	type extended struct {
+		F11 *Numeric `field:"11" length:"6" encode:"ascii"`
+		F65 *Binary  `field:"65" length:"8"`
+		F70 *Numeric `field:"70" length:"3" encode:"ascii"`
+	}
+	msg = NewMessage("0800", &extended{
+		F11: NewNumeric("000001"),
+		F65: NewBinary(make([]byte, 8)),
+		F70: NewNumeric("301"),
+	})
+	msg.SecondaryBitmap = SecondaryBitmapAuto
+	bitmaps := msg.Bitmaps()
+	assert.Len(t, bitmaps, 2)
+	assert.Equal(t, []byte{0x84, 0, 0, 0, 0, 0, 0, 0}, bitmaps[1].Raw)
+	assert.Equal(t, []int{70}, bitmaps[1].Fields)
+}
+
+func TestMessageBitmapsDecoded(t *testing.T) {
+	msg := NewMessage("0200", &TestISO{F11: NewNumeric("000001")})
+	msg.SecondaryBitmap = SecondaryBitmapAlways
+	b, ext, err := msg.BytesWithExtents()
+	assert.Nil(t, err)
+	wire := []BitmapInfo{
+		{Index: 0, Raw: []byte{0x80, 0x20, 0, 0, 0, 0, 0, 0}, Fields: []int{11}},
+		{Index: 1, Raw: []byte{0, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	assert.Equal(t, wire, ext.Bitmaps())
+
+	// an all zero secondary bitmap is read and reported, whatever the mode
+	p := &Parser{SecondaryBitmap: SecondaryBitmapAuto}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	parsed, ext, err := p.ParseWithExtents(b)
+	assert.Nil(t, err)
+	assert.Equal(t, wire, ext.Bitmaps())
+	// Message.Bitmaps gives the bitmaps Bytes would emit again
+	assert.Equal(t, []BitmapInfo{
+		{Index: 0, Raw: []byte{0x00, 0x20, 0, 0, 0, 0, 0, 0}, Fields: []int{11}},
+	}, parsed.Bitmaps())
+
+	p.SecondaryBitmap = SecondaryBitmapManual
+	parsed, ext, err = p.ParseWithExtents(b)
+	assert.Nil(t, err)
+	assert.Equal(t, wire, ext.Bitmaps())
+	assert.Equal(t, wire, parsed.Bitmaps())
+
+	loaded := NewMessage("", &TestISO{F11: &Numeric{}})
+	ext, err = loaded.LoadWithExtents(b)
+	assert.Nil(t, err)
+	assert.Equal(t, wire, ext.Bitmaps())
+
+	// the bitmaps are known with an Encoder too
+	p = &Parser{Encoder: DefaultEncoder{}}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	_, ext, err = p.ParseWithExtents(b)
+	assert.Nil(t, err)
+	assert.Equal(t, wire, ext.Bitmaps())
+
+	msg.Presence = BitmapPresence{}
+	assert.Nil(t, msg.Bitmaps())
+	_, ext, err = msg.BytesWithExtents()
+	assert.Nil(t, err)
+	assert.Nil(t, ext.Bitmaps())
+}
@@ -0,0 +1,91 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MessageBuilder sets the fields of a message and checks that the required
+// ones are present before the message is packed with Bytes
+type MessageBuilder struct {
+	msg      *Message
+	required map[int]bool
+	errs     []string
+}
+
+// NewMessageBuilder returns a builder for a message of type mti whose
+// fields are held by data, a pointer to a tagged struct
+func NewMessageBuilder(mti string, data interface{}) *MessageBuilder {
+	return &MessageBuilder{
+		msg:      NewMessage(mti, data),
+		required: make(map[int]bool),
+	}
+}
+
+// RequireField marks field n as required by Build
+func (b *MessageBuilder) RequireField(n int) *MessageBuilder {
+	b.required[n] = true
+	return b
+}
+
+// Field sets field n to v. A field which is not defined by the data struct
+// or has another type is reported by Build.
+func (b *MessageBuilder) Field(n int, v Iso8583Type) *MessageBuilder {
+	f, err := structField(b.msg.Data, n)
+	if err != nil {
+		b.errs = append(b.errs, err.Error())
+		return b
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !rv.Type().AssignableTo(f.Type()) {
+		b.errs = append(b.errs, fmt.Sprintf("field %d: expected %s, got %T", n, f.Type(), v))
+		return b
+	}
+	f.Set(rv)
+	return b
+}
+
+// Build returns the message, or an error listing every invalid Field call
+// and every required field which is absent or empty. Missing fields are
+// reported as ErrFieldNotSet.
+func (b *MessageBuilder) Build() (*Message, error) {
+	ns := make([]int, 0, len(b.required))
+	for n := range b.required {
+		ns = append(ns, n)
+	}
+	sort.Ints(ns)
+	fields, err := b.msg.fields()
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, n := range ns {
+		info, ok := fields[n]
+		if !ok || info.Field.IsEmpty() {
+			missing = append(missing, strconv.Itoa(n))
+		}
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("fields %s: %w", strings.Join(missing, ", "), ErrFieldNotSet)
+	}
+	if len(b.errs) > 0 {
+		if err != nil {
+			return nil, fmt.Errorf("%s; %w", strings.Join(b.errs, "; "), err)
+		}
+		return nil, errors.New(strings.Join(b.errs, "; "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.msg, nil
+}
+
+// BuildUnsafe returns the message without any check, for tests which need
+// incomplete messages
+func (b *MessageBuilder) BuildUnsafe() *Message {
+	return b.msg
+}
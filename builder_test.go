@@ -0,0 +1,55 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type builderData struct {
+	F2  *Llnumeric    `field:"2" length:"19" encode:"ascii,ascii"`
+	F3  *Numeric      `field:"3" length:"6" encode:"ascii"`
+	F4  *Numeric      `field:"4" length:"12" encode:"ascii"`
+	F11 *Numeric      `field:"11" length:"6" encode:"ascii"`
+	F41 *Alphanumeric `field:"41" length:"8"`
+}
+
+func TestMessageBuilder(t *testing.T) {
+	b := NewMessageBuilder("0200", &builderData{}).
+		RequireField(3).RequireField(11).RequireField(41).RequireField(4).
+		Field(4, NewNumeric("1000"))
+	msg, err := b.Build()
+	assert.Nil(t, msg)
+	assert.True(t, errors.Is(err, ErrFieldNotSet))
+	assert.EqualError(t, err, "fields 3, 11, 41: field not set")
+
+	assert.NotNil(t, b.BuildUnsafe())
+
+	msg, err = b.Field(3, NewNumeric("000000")).
+		Field(11, NewNumeric("000001")).
+		Field(41, NewAlphanumeric("TERM0001")).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "000001", msg.Data.(*builderData).F11.Value)
+	_, err = msg.Bytes()
+	assert.NoError(t, err)
+}
+
+func TestMessageBuilderBadField(t *testing.T) {
+	_, err := NewMessageBuilder("0200", &builderData{}).
+		Field(5, NewNumeric("1")).
+		Field(3, NewAlphanumeric("x")).
+		Build()
+	assert.EqualError(t, err, "field 5 not defined; field 3: expected *iso8583.Numeric, got *iso8583.Alphanumeric")
+}
+
+func TestMessageBuilderAllErrors(t *testing.T) {
+	_, err := NewMessageBuilder("0200", &builderData{}).
+		RequireField(3).RequireField(11).
+		Field(5, NewNumeric("1")).
+		Field(11, NewNumeric("000001")).
+		Build()
+	assert.True(t, errors.Is(err, ErrFieldNotSet))
+	assert.EqualError(t, err, "field 5 not defined; fields 3: field not set")
+}
@@ -0,0 +1,182 @@
+package iso8583
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CaptureLayout describes the columns of a capture log line, such as
+//
+//	2021-07-09T14:05:30.123Z,in,30323030...
+//
+// as extracted from pcaps: timestamp, direction and message in hex
+type CaptureLayout struct {
+	Separator string
+
+	// TimeColumn, DirectionColumn and DataColumn are the 0-based indexes
+	// of the columns
+	TimeColumn      int
+	DirectionColumn int
+	DataColumn      int
+
+	// TimeFormat is the layout of the timestamp, as for time.Parse
+	TimeFormat string
+}
+
+// validate checks that the columns are distinct and not negative
+func (l CaptureLayout) validate() error {
+	if l.Separator == "" {
+		return errors.New("capture layout: separator is required")
+	}
+	cols := []int{l.TimeColumn, l.DirectionColumn, l.DataColumn}
+	for i, c := range cols {
+		if c < 0 {
+			return fmt.Errorf("capture layout: invalid column %d", c)
+		}
+		for _, other := range cols[:i] {
+			if c == other {
+				return fmt.Errorf("capture layout: column %d is used twice", c)
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultCaptureLayout is "timestamp,direction,hex" with RFC 3339
+// timestamps
+var DefaultCaptureLayout = CaptureLayout{
+	Separator:       ",",
+	TimeColumn:      0,
+	DirectionColumn: 1,
+	DataColumn:      2,
+	TimeFormat:      time.RFC3339Nano,
+}
+
+// CaptureRecord is a message of a capture log
+type CaptureRecord struct {
+	// Line is the line number of the record in the log, from 1
+	Line      int
+	Time      time.Time
+	Direction string
+	Message   *Message
+}
+
+// CaptureReader reads capture logs. Blank lines and lines starting with #
+// are ignored.
+type CaptureReader struct {
+	Parser *Parser
+	Layout CaptureLayout
+
+	// Lenient skips malformed records instead of failing. The errors of
+	// the skipped records are kept in Skipped.
+	Lenient bool
+	Skipped []error
+}
+
+// NewCaptureReader returns a reader of logs in DefaultCaptureLayout whose
+// messages are parsed with p
+func NewCaptureReader(p *Parser) *CaptureReader {
+	return &CaptureReader{Parser: p, Layout: DefaultCaptureLayout}
+}
+
+// ReadAll reads all records of the log from r
+func (c *CaptureReader) ReadAll(r io.Reader) ([]CaptureRecord, error) {
+	if c.Parser == nil {
+		return nil, errors.New("parser is required")
+	}
+	if err := c.Layout.validate(); err != nil {
+		return nil, err
+	}
+	var records []CaptureRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rec, err := c.parseLine(text)
+		if err != nil {
+			err = fmt.Errorf("line %d: %w", line, err)
+			if !c.Lenient {
+				return records, err
+			}
+			c.Skipped = append(c.Skipped, err)
+			continue
+		}
+		rec.Line = line
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+func (c *CaptureReader) parseLine(text string) (CaptureRecord, error) {
+	l := c.Layout
+	cols := strings.Split(text, l.Separator)
+	for _, i := range []int{l.TimeColumn, l.DirectionColumn, l.DataColumn} {
+		if i < 0 || i >= len(cols) {
+			return CaptureRecord{}, fmt.Errorf("%d columns, column %d expected", len(cols), i)
+		}
+	}
+	t, err := time.Parse(l.TimeFormat, strings.TrimSpace(cols[l.TimeColumn]))
+	if err != nil {
+		return CaptureRecord{}, err
+	}
+	raw, err := hex.DecodeString(strings.TrimSpace(cols[l.DataColumn]))
+	if err != nil {
+		return CaptureRecord{}, err
+	}
+	msg, err := c.Parser.Parse(raw)
+	if err != nil {
+		return CaptureRecord{}, err
+	}
+	return CaptureRecord{
+		Time:      t,
+		Direction: strings.TrimSpace(cols[l.DirectionColumn]),
+		Message:   msg,
+	}, nil
+}
+
+// CaptureWriter writes messages as a capture log, which CaptureReader
+// reads back for replay
+type CaptureWriter struct {
+	W      io.Writer
+	Layout CaptureLayout
+}
+
+// NewCaptureWriter returns a writer of logs in DefaultCaptureLayout
+func NewCaptureWriter(w io.Writer) *CaptureWriter {
+	return &CaptureWriter{W: w, Layout: DefaultCaptureLayout}
+}
+
+// Write packs msg and writes it as a record of the log
+func (c *CaptureWriter) Write(t time.Time, direction string, msg *Message) error {
+	b, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	l := c.Layout
+	if err := l.validate(); err != nil {
+		return err
+	}
+	n := l.TimeColumn
+	if l.DirectionColumn > n {
+		n = l.DirectionColumn
+	}
+	if l.DataColumn > n {
+		n = l.DataColumn
+	}
+	cols := make([]string, n+1)
+	cols[l.TimeColumn] = t.Format(l.TimeFormat)
+	cols[l.DirectionColumn] = direction
+	cols[l.DataColumn] = hex.EncodeToString(b)
+	_, err = io.WriteString(c.W, strings.Join(cols, l.Separator)+"\n")
+	return err
+}
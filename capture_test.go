@@ -0,0 +1,110 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapture(t *testing.T) {
+	req := NewMessage("0200", &TestISO{
+		F2:  NewLlnumeric("4111111111111111"),
+		F3:  NewNumeric("000000"),
+		F11: NewNumeric("000001"),
+	})
+	resp := NewMessage("0210", &TestISO{
+		F11: NewNumeric("000001"),
+		F39: NewAlphanumeric("00"),
+	})
+	at := time.Date(2021, 7, 9, 14, 5, 30, 123000000, time.UTC)
+
+	var log bytes.Buffer
+	w := NewCaptureWriter(&log)
+	assert.Nil(t, w.Write(at, "out", req))
+	log.WriteString("2021-07-09T14:05:30.200Z,in,3032zz\n")
+	assert.Nil(t, w.Write(at.Add(time.Second), "in", resp))
+	assert.True(t, strings.HasPrefix(log.String(), "2021-07-09T14:05:30.123Z,out,30323030"))
+
+	p := &Parser{}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	assert.Nil(t, p.Register("0210", &TestISO{}))
+
+	_, err := NewCaptureReader(p).ReadAll(strings.NewReader(log.String()))
+	assert.EqualError(t, err, "line 2: encoding/hex: invalid byte: U+007A 'z'")
+
+	r := NewCaptureReader(p)
+	r.Lenient = true
+	records, err := r.ReadAll(strings.NewReader("# captured\n" + log.String()))
+	assert.Nil(t, err)
+	assert.Len(t, records, 2)
+	assert.Len(t, r.Skipped, 1)
+	assert.EqualError(t, r.Skipped[0], "line 3: encoding/hex: invalid byte: U+007A 'z'")
+
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, at, records[0].Time)
+	assert.Equal(t, "out", records[0].Direction)
+	assert.Equal(t, "4111111111111111", records[0].Message.Data.(*TestISO).F2.Value)
+	assert.Equal(t, 4, records[1].Line)
+	assert.Equal(t, "in", records[1].Direction)
+	assert.Equal(t, "0210", records[1].Message.Mti)
+	assert.Equal(t, "00", records[1].Message.Data.(*TestISO).F39.Value)
+}
+
+func TestCaptureLayout(t *testing.T) {
+	p := &Parser{}
+	assert.Nil(t, p.Register("0210", &TestISO{}))
+	msg := NewMessage("0210", &TestISO{F39: NewAlphanumeric("05")})
+	layout := CaptureLayout{Separator: "|", DataColumn: 0, DirectionColumn: 2, TimeColumn: 3, TimeFormat: "15:04:05"}
+
+	var log bytes.Buffer
+	w := &CaptureWriter{W: &log, Layout: layout}
+	assert.Nil(t, w.Write(time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC), "in", msg))
+	assert.Regexp(t, `^[0-9a-f]+\|\|in\|09:30:00\n$`, log.String())
+
+	r := &CaptureReader{Parser: p, Layout: layout}
+	records, err := r.ReadAll(&log)
+	assert.Nil(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "05", records[0].Message.Data.(*TestISO).F39.Value)
+
+	_, err = r.ReadAll(strings.NewReader("3032|in\n"))
+	assert.EqualError(t, err, "line 1: 2 columns, column 3 expected")
+
+	for _, bad := range []CaptureLayout{
+		{Separator: "|", DataColumn: -1, DirectionColumn: 2, TimeColumn: 3},
+		{Separator: "|", DataColumn: 0, DirectionColumn: 0, TimeColumn: 3},
+		{DataColumn: 0, DirectionColumn: 1, TimeColumn: 2},
+	} {
+		w.Layout = bad
+		assert.NotNil(t, w.Write(time.Now(), "in", msg), "%+v", bad)
+		r.Layout = bad
+		_, err = r.ReadAll(strings.NewReader(""))
+		assert.NotNil(t, err, "%+v", bad)
+	}
+	w.Layout = CaptureLayout{Separator: "|", DataColumn: -1, DirectionColumn: 2, TimeColumn: 3}
+	assert.EqualError(t, w.Write(time.Now(), "in", msg), "capture layout: invalid column -1")
+}
+
+func TestCaptureDecodeError(t *testing.T) {
+	p := &Parser{}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	b, err := NewMessage("0200", &TestISO{F2: NewLlnumeric("4111111111111111")}).Bytes()
+	assert.Nil(t, err)
+	line := "2021-07-09T14:05:30.123Z,in," + hex.EncodeToString(b[:len(b)-2]) + "\n"
+
+	var decodeErr *DecodeError
+	_, err = NewCaptureReader(p).ReadAll(strings.NewReader(line))
+	assert.True(t, errors.As(err, &decodeErr), "%v", err)
+
+	r := NewCaptureReader(p)
+	r.Lenient = true
+	_, err = r.ReadAll(strings.NewReader(line))
+	assert.Nil(t, err)
+	assert.Len(t, r.Skipped, 1)
+	assert.True(t, errors.As(r.Skipped[0], &decodeErr))
+}
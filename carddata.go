@@ -0,0 +1,72 @@
+package iso8583
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Inconsistency is a finding of CheckCardDataConsistency
+type Inconsistency struct {
+	// Field is the field found inconsistent
+	Field  int
+	Reason string
+}
+
+func (i Inconsistency) String() string {
+	return fmt.Sprintf("field %d: %s", i.Field, i.Reason)
+}
+
+// CheckCardDataConsistency compares the card data of the message: the PAN
+// (field 2) and expiry date (field 14) with the track 2 data (field 35),
+// the Luhn check digit of the PAN and the PAN sequence number (field 23).
+// Checks for absent fields are skipped. It returns nil if nothing is wrong.
+func (m *Message) CheckCardDataConsistency() []Inconsistency {
+	var found []Inconsistency
+	values := make(map[int]string)
+	for n, f := range m.GetAvailableFields(2, 14, 23, 35) {
+		values[n] = fieldString(f)
+	}
+
+	pan, hasPAN := values[2]
+	if track, ok := values[35]; ok {
+		end := strings.IndexAny(track, "=D")
+		if end == -1 {
+			found = append(found, Inconsistency{35, "track 2 has no field separator"})
+		} else {
+			trackPAN := track[:end]
+			if hasPAN && pan != trackPAN {
+				found = append(found, Inconsistency{2, "PAN does not match track 2"})
+			}
+			if !hasPAN {
+				pan, hasPAN = trackPAN, true
+			}
+			if expiry, ok := values[14]; ok && len(track) >= end+5 && expiry != track[end+1:end+5] {
+				found = append(found, Inconsistency{14, "expiry date does not match track 2"})
+			}
+		}
+	}
+
+	if hasPAN && NewNumeric(pan).ValidateCheckDigit("luhn") != nil {
+		n := 2
+		if _, ok := values[2]; !ok {
+			n = 35
+		}
+		found = append(found, Inconsistency{n, "PAN fails Luhn check"})
+	}
+
+	if seq, ok := values[23]; ok {
+		if _, err := checkDigitInput(seq); err != nil || len(seq) != 3 {
+			found = append(found, Inconsistency{23, "PAN sequence number is not 3 digits"})
+		}
+	}
+	return found
+}
+
+// cardDataError joins the findings into one error
+func cardDataError(found []Inconsistency) error {
+	reasons := make([]string, len(found))
+	for i, f := range found {
+		reasons[i] = f.String()
+	}
+	return fmt.Errorf("card data inconsistent: %s", strings.Join(reasons, "; "))
+}
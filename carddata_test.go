@@ -0,0 +1,71 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCardDataConsistency(t *testing.T) {
+	type data struct {
+		F2  *Llnumeric `field:"2" length:"19"`
+		F14 *Numeric   `field:"14" length:"4"`
+		F23 *Numeric   `field:"23" length:"3"`
+		F35 *Llnumeric `field:"35" length:"37"`
+	}
+
+	msg := NewMessage("0200", &data{
+		F2:  NewLlnumeric("4111111111111111"),
+		F14: NewNumeric("2512"),
+		F23: NewNumeric("001"),
+		F35: NewLlnumeric("4111111111111111=25121010000000000"),
+	})
+	assert.Nil(t, msg.CheckCardDataConsistency())
+
+	msg = NewMessage("0200", &data{
+		F2:  NewLlnumeric("5555555555554444"),
+		F14: NewNumeric("2612"),
+		F35: NewLlnumeric("4111111111111111D25121010000000000"),
+	})
+	found := msg.CheckCardDataConsistency()
+	assert.Equal(t, []Inconsistency{
+		{2, "PAN does not match track 2"},
+		{14, "expiry date does not match track 2"},
+	}, found)
+	assert.Equal(t, "field 2: PAN does not match track 2", found[0].String())
+
+	// without track data only the PAN itself is checked
+	msg = NewMessage("0200", &data{
+		F2:  NewLlnumeric("4111111111111112"),
+		F14: NewNumeric("2612"),
+		F23: NewNumeric("1"),
+	})
+	assert.Equal(t, []Inconsistency{
+		{2, "PAN fails Luhn check"},
+		{23, "PAN sequence number is not 3 digits"},
+	}, msg.CheckCardDataConsistency())
+
+	msg = NewMessage("0200", &data{F35: NewLlnumeric("4111111111111112=2512")})
+	assert.Equal(t, []Inconsistency{{35, "PAN fails Luhn check"}}, msg.CheckCardDataConsistency())
+
+	msg = NewMessage("0200", &data{F35: NewLlnumeric("41111111111111112512")})
+	assert.Equal(t, []Inconsistency{{35, "track 2 has no field separator"}}, msg.CheckCardDataConsistency())
+}
+
+func TestParserStrictCardData(t *testing.T) {
+	raw, err := NewMessage("0200", &TestISO{
+		F2:  NewLlnumeric("5555555555554444"),
+		F35: NewLlnumeric("4111111111111111=2512"),
+	}).Bytes()
+	assert.Nil(t, err)
+
+	parser := Parser{}
+	assert.Nil(t, parser.Register("0200", &TestISO{}))
+	_, err = parser.Parse(raw)
+	assert.Nil(t, err)
+
+	parser.StrictCardData = true
+	msg, err := parser.Parse(raw)
+	assert.EqualError(t, err, "card data inconsistent: field 2: PAN does not match track 2")
+	assert.NotNil(t, msg)
+}
@@ -0,0 +1,163 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCheckDigit is returned by ValidateCheckDigit when the check digits of a
+// value are wrong
+var ErrCheckDigit = errors.New("check digit does not match")
+
+var (
+	verhoeffD = [10][10]byte{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]byte{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 8, 7, 6, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+	verhoeffInv = [10]byte{0, 4, 3, 2, 1, 5, 7, 6, 9, 8}
+)
+
+// CheckDigit returns the check digit of the value, which does not contain
+// it yet, as an ASCII digit. algorithm is "luhn" (ISO/IEC 7812, used for
+// PANs) or "verhoeff". The two digits of "mod97" are returned by
+// CheckDigits.
+func (n *Numeric) CheckDigit(algorithm string) (byte, error) {
+	if algorithm == "mod97" {
+		return 0, errors.New("mod97 has two check digits, use CheckDigits")
+	}
+	d, err := n.CheckDigits(algorithm)
+	if err != nil {
+		return 0, err
+	}
+	return d[0], nil
+}
+
+// CheckDigits returns the check digits of the value, which does not contain
+// them yet. algorithm is "luhn", "verhoeff" or "mod97" (ISO 7064 MOD 97-10,
+// as used by IBAN with letters converted to digits).
+func (n *Numeric) CheckDigits(algorithm string) (string, error) {
+	if n == nil {
+		return "", &ErrNilField{"Numeric"}
+	}
+	digits, err := checkDigitInput(n.Digits())
+	if err != nil {
+		return "", err
+	}
+	switch algorithm {
+	case "luhn":
+		return string('0' + luhn(digits, true)), nil
+	case "verhoeff":
+		return string('0' + verhoeff(digits, true)), nil
+	case "mod97":
+		return fmt.Sprintf("%02d", 98-mod97(append(digits, 0, 0))), nil
+	}
+	return "", fmt.Errorf("unknown check digit algorithm %q", algorithm)
+}
+
+// ValidateCheckDigit checks the trailing check digits of the value with the
+// algorithm ("luhn", "verhoeff" or "mod97"). It returns ErrCheckDigit if
+// they are wrong.
+func (n *Numeric) ValidateCheckDigit(algorithm string) error {
+	if n == nil {
+		return &ErrNilField{"Numeric"}
+	}
+	digits, err := checkDigitInput(n.Digits())
+	if err != nil {
+		return err
+	}
+	valid := false
+	switch algorithm {
+	case "luhn":
+		valid = len(digits) > 1 && luhn(digits, false) == 0
+	case "verhoeff":
+		valid = len(digits) > 1 && verhoeff(digits, false) == 0
+	case "mod97":
+		valid = len(digits) > 2 && mod97(digits) == 1
+	default:
+		return fmt.Errorf("unknown check digit algorithm %q", algorithm)
+	}
+	if !valid {
+		return ErrCheckDigit
+	}
+	return nil
+}
+
+func checkDigitInput(value string) ([]byte, error) {
+	if value == "" {
+		return nil, errors.New("value is empty")
+	}
+	digits := make([]byte, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return nil, fmt.Errorf("invalid digit %q at %d", value[i], i)
+		}
+		digits[i] = value[i] - '0'
+	}
+	return digits, nil
+}
+
+// luhn returns the check digit of digits when generate is set, and the
+// remainder of digits, which is 0 for a valid number, otherwise
+func luhn(digits []byte, generate bool) byte {
+	sum := 0
+	double := generate
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i])
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	if generate {
+		return byte((10 - sum%10) % 10)
+	}
+	return byte(sum % 10)
+}
+
+// verhoeff returns the check digit of digits when generate is set, and the
+// checksum of digits, which is 0 for a valid number, otherwise
+func verhoeff(digits []byte, generate bool) byte {
+	offset := 0
+	if generate {
+		offset = 1
+	}
+	var c byte
+	for i := 0; i < len(digits); i++ {
+		d := digits[len(digits)-1-i]
+		c = verhoeffD[c][verhoeffP[(i+offset)%8][d]]
+	}
+	if generate {
+		return verhoeffInv[c]
+	}
+	return c
+}
+
+func mod97(digits []byte) int {
+	r := 0
+	for _, d := range digits {
+		r = (r*10 + int(d)) % 97
+	}
+	return r
+}
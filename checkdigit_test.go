@@ -0,0 +1,43 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericCheckDigit(t *testing.T) {
+	d, err := NewNumeric("411111111111111").CheckDigit("luhn")
+	assert.Nil(t, err)
+	assert.Equal(t, byte('1'), d)
+	d, err = NewNumeric("7992739871").CheckDigit("luhn")
+	assert.Nil(t, err)
+	assert.Equal(t, byte('3'), d)
+
+	assert.Nil(t, NewNumeric("4111111111111111").ValidateCheckDigit("luhn"))
+	assert.Nil(t, NewNumeric("5555555555554444").ValidateCheckDigit("luhn"))
+	assert.Nil(t, NewNumeric("79927398713").ValidateCheckDigit("luhn"))
+	assert.Equal(t, ErrCheckDigit, NewNumeric("4111111111111112").ValidateCheckDigit("luhn"))
+	assert.Equal(t, ErrCheckDigit, NewNumeric("79927398710").ValidateCheckDigit("luhn"))
+
+	d, err = NewNumeric("236").CheckDigit("verhoeff")
+	assert.Nil(t, err)
+	assert.Equal(t, byte('3'), d)
+	assert.Nil(t, NewNumeric("2363").ValidateCheckDigit("verhoeff"))
+	assert.Equal(t, ErrCheckDigit, NewNumeric("2364").ValidateCheckDigit("verhoeff"))
+	assert.Equal(t, ErrCheckDigit, NewNumeric("2633").ValidateCheckDigit("verhoeff"))
+
+	// GB82 WEST 1234 5698 7654 32, rearranged with letters as digits
+	assert.Nil(t, NewNumeric("3214282912345698765432161182").ValidateCheckDigit("mod97"))
+	assert.Equal(t, ErrCheckDigit, NewNumeric("3214282912345698765432161183").ValidateCheckDigit("mod97"))
+	digits, err := NewNumeric("32142829123456987654321611").CheckDigits("mod97")
+	assert.Nil(t, err)
+	assert.Equal(t, "82", digits)
+	_, err = NewNumeric("1234").CheckDigit("mod97")
+	assert.EqualError(t, err, "mod97 has two check digits, use CheckDigits")
+
+	_, err = NewNumeric("1234").CheckDigit("damm")
+	assert.EqualError(t, err, `unknown check digit algorithm "damm"`)
+	assert.EqualError(t, NewNumeric("12A4").ValidateCheckDigit("luhn"), `invalid digit 'A' at 2`)
+	assert.EqualError(t, NewNumeric("").ValidateCheckDigit("luhn"), "value is empty")
+}
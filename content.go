@@ -0,0 +1,190 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func init() {
+	registerFeature("content_encoding")
+}
+
+// ContentEncoding is the encoding of the content of a variable length field
+type ContentEncoding int
+
+const (
+	// EncodingRaw is uninterpreted bytes, displayed in hex
+	EncodingRaw ContentEncoding = iota
+	// EncodingUTF8 is UTF-8 text
+	EncodingUTF8
+	// EncodingLatin1 is ISO 8859-1 text
+	EncodingLatin1
+	// EncodingBCD is packed decimal digits
+	EncodingBCD
+	// EncodingBestEffort is text in an unknown charset, such as national
+	// data in field 59: UTF-8 is tried first, then Latin-1, then the value
+	// is shown in hex. The bytes are kept as they are.
+	EncodingBestEffort
+)
+
+func (e ContentEncoding) String() string {
+	switch e {
+	case EncodingRaw:
+		return "raw"
+	case EncodingUTF8:
+		return "utf8"
+	case EncodingLatin1:
+		return "latin1"
+	case EncodingBCD:
+		return "bcd"
+	case EncodingBestEffort:
+		return "best effort"
+	}
+	return "unknown"
+}
+
+// parseContentEncoding returns the encoding named by a content tag, or -1
+func parseContentEncoding(str string) ContentEncoding {
+	switch str {
+	case "raw":
+		return EncodingRaw
+	case "utf8":
+		return EncodingUTF8
+	case "latin1":
+		return EncodingLatin1
+	case "bcd":
+		return EncodingBCD
+	case "besteffort":
+		return EncodingBestEffort
+	}
+	return -1
+}
+
+// NewLllvarUTF8 create new Lllvar field holding s as UTF-8
+func NewLllvarUTF8(s string) *Lllvar {
+	return &Lllvar{Value: []byte(s), Encoding: EncodingUTF8}
+}
+
+// StringValue decodes the value with the encoding of the field. Raw values
+// are returned as they are.
+func (l *Lllvar) StringValue() (string, error) {
+	if l == nil {
+		return "", &ErrNilField{"Lllvar"}
+	}
+	switch l.Encoding {
+	case EncodingBestEffort:
+		return l.String(), nil
+	case EncodingRaw:
+		return string(l.Value), nil
+	case EncodingUTF8:
+		if !utf8.Valid(l.Value) {
+			return "", errors.New("value is not valid UTF-8")
+		}
+		return string(l.Value), nil
+	case EncodingLatin1:
+		// every Latin-1 byte is the code point of the same value
+		runes := make([]rune, len(l.Value))
+		for i, b := range l.Value {
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	case EncodingBCD:
+		s := hex.EncodeToString(l.Value)
+		if strings.Trim(s, "0123456789") != "" {
+			return "", errors.New("value is not valid BCD")
+		}
+		return s, nil
+	}
+	return "", &ErrInvalidEncoder{int(l.Encoding)}
+}
+
+// String returns the value for display: raw values and values which can not
+// be decoded are shown in uppercase hex
+func (l *Lllvar) String() string {
+	if l == nil {
+		return ""
+	}
+	if l.Encoding == EncodingBestEffort {
+		l = &Lllvar{Value: l.Value, Encoding: l.Interpretation()}
+	}
+	if l.Encoding != EncodingRaw {
+		if s, err := l.StringValue(); err == nil {
+			return s
+		}
+	}
+	return strings.ToUpper(hex.EncodeToString(l.Value))
+}
+
+// text returns the value as String does, except that raw values are
+// returned as they are. Message.String and GetString show it.
+func (l *Lllvar) text() string {
+	if l != nil && l.Encoding == EncodingRaw {
+		return string(l.Value)
+	}
+	return l.String()
+}
+
+// Interpretation returns the encoding the value is decoded with. For
+// EncodingBestEffort it is EncodingUTF8 or EncodingLatin1 for text, and
+// EncodingRaw for values shown in hex.
+func (l *Lllvar) Interpretation() ContentEncoding {
+	if l == nil {
+		return EncodingRaw
+	}
+	if l.Encoding != EncodingBestEffort {
+		return l.Encoding
+	}
+	if utf8.Valid(l.Value) && isText(string(l.Value)) {
+		return EncodingUTF8
+	}
+	runes := make([]rune, len(l.Value))
+	for i, b := range l.Value {
+		runes[i] = rune(b)
+	}
+	if isText(string(runes)) {
+		return EncodingLatin1
+	}
+	return EncodingRaw
+}
+
+// Warning describes how a best effort value was decoded when it was not
+// valid UTF-8, or returns an empty string
+func (l *Lllvar) Warning() string {
+	if l == nil || l.Encoding != EncodingBestEffort {
+		return ""
+	}
+	switch l.Interpretation() {
+	case EncodingLatin1:
+		return "value is not valid UTF-8, decoded as Latin-1"
+	case EncodingRaw:
+		return "value is not text, shown in hex"
+	}
+	return ""
+}
+
+// isText reports whether s holds no control characters but whitespace
+func isText(s string) bool {
+	for _, r := range s {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalJSON encodes the field as a JSON string of String. A best effort
+// value is encoded as an object with its text, hex and interpretation.
+func (l *Lllvar) MarshalJSON() ([]byte, error) {
+	if l != nil && l.Encoding == EncodingBestEffort {
+		return json.Marshal(struct {
+			Text           string `json:"text"`
+			Hex            string `json:"hex"`
+			Interpretation string `json:"interpretation"`
+		}{l.String(), strings.ToUpper(hex.EncodeToString(l.Value)), l.Interpretation().String()})
+	}
+	return json.Marshal(l.String())
+}
@@ -0,0 +1,171 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLllvarEncoding(t *testing.T) {
+	raw := NewLllvar([]byte{0x01, 0xAB, 'z'})
+	assert.Equal(t, "01AB7A", raw.String())
+	s, err := raw.StringValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "\x01\xabz", s)
+
+	utf := NewLllvarUTF8("Привет, 你好")
+	assert.Equal(t, "Привет, 你好", utf.String())
+	s, err = utf.StringValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "Привет, 你好", s)
+
+	bad := &Lllvar{Value: []byte{0xFF, 'a'}, Encoding: EncodingUTF8}
+	_, err = bad.StringValue()
+	assert.EqualError(t, err, "value is not valid UTF-8")
+	assert.Equal(t, "FF61", bad.String())
+
+	latin1 := &Lllvar{Value: []byte{'c', 'a', 'f', 0xE9, ' ', 0xA3, '5'}, Encoding: EncodingLatin1}
+	assert.Equal(t, "café £5", latin1.String())
+
+	bcd := &Lllvar{Value: []byte{0x12, 0x34, 0x05}, Encoding: EncodingBCD}
+	assert.Equal(t, "123405", bcd.String())
+	bcd.Value = []byte{0x1F}
+	_, err = bcd.StringValue()
+	assert.EqualError(t, err, "value is not valid BCD")
+
+	j, err := json.Marshal(map[string]*Lllvar{"raw": raw, "latin1": latin1})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"latin1":"café £5","raw":"01AB7A"}`, string(j))
+
+	// the wire format does not depend on the encoding
+	b1, err := latin1.Bytes(ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	b2, err := NewLllvar(latin1.Value).Bytes(ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, b2, b1)
+
+	c := latin1.Copy().(*Lllvar)
+	assert.Equal(t, EncodingLatin1, c.Encoding)
+}
+
+func TestLllvarEncodingMessage(t *testing.T) {
+	type data struct {
+		F59 *Lllvar `field:"59" length:"999" encode:"ascii,ascii"`
+	}
+	msg := NewMessage("0200", &data{&Lllvar{Value: []byte{0x12, 0x34}, Encoding: EncodingBCD}})
+	s, err := msg.GetString(59)
+	assert.Nil(t, err)
+	assert.Equal(t, "1234", s)
+	assert.Contains(t, msg.String(), "1234")
+
+	msg.Data.(*data).F59 = &Lllvar{Value: []byte{'c', 'a', 'f', 0xE9}, Encoding: EncodingLatin1}
+	s, err = msg.GetString(59)
+	assert.Nil(t, err)
+	assert.Equal(t, "café", s)
+
+	// raw values are read back as they were set
+	assert.Nil(t, msg.SetFieldFromString(59, "abc"))
+	msg.Data.(*data).F59.Encoding = EncodingRaw
+	s, err = msg.GetString(59)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", s)
+
+	bad := &Lllvar{Value: []byte("a"), Encoding: ContentEncoding(99)}
+	_, err = bad.StringValue()
+	assert.IsType(t, &ErrInvalidEncoder{}, err)
+	assert.Equal(t, "61", bad.String())
+}
+
+func TestLllvarBestEffort(t *testing.T) {
+	type data struct {
+		F59 *Lllvar `field:"59" length:"999" encode:"ascii,ascii"`
+	}
+	for _, c := range []struct {
+		value          []byte
+		text           string
+		interpretation ContentEncoding
+		warning        string
+		json           string
+	}{
+		{[]byte("Bangkok 10110"), "Bangkok 10110", EncodingUTF8, "",
+			`{"text":"Bangkok 10110","hex":"42616E676B6F6B203130313130","interpretation":"utf8"}`},
+		{[]byte{'M', 0xFC, 'n', 'c', 'h', 'e', 'n'}, "München", EncodingLatin1, "value is not valid UTF-8, decoded as Latin-1",
+			`{"text":"München","hex":"4DFC6E6368656E","interpretation":"latin1"}`},
+		{[]byte{0x00, 0x01, 0xFF, 0x10}, "0001FF10", EncodingRaw, "value is not text, shown in hex",
+			`{"text":"0001FF10","hex":"0001FF10","interpretation":"raw"}`},
+	} {
+		b, err := NewMessage("0200", &data{F59: NewLllvar(c.value)}).Bytes()
+		assert.Nil(t, err)
+
+		f := &Lllvar{Encoding: EncodingBestEffort}
+		msg := NewMessage("", &data{F59: f})
+		assert.Nil(t, msg.Load(b))
+		assert.Equal(t, c.value, f.Value)
+		assert.Equal(t, c.interpretation, f.Interpretation())
+		assert.Equal(t, c.warning, f.Warning())
+		s, err := msg.GetString(59)
+		assert.Nil(t, err)
+		assert.Equal(t, c.text, s)
+		j, err := json.Marshal(f)
+		assert.Nil(t, err)
+		assert.Equal(t, c.json, string(j))
+
+		// the raw bytes are encoded again, whatever the interpretation
+		again, err := msg.Bytes()
+		assert.Nil(t, err)
+		assert.Equal(t, b, again)
+	}
+}
+
+func TestLllvarContentTag(t *testing.T) {
+	type data struct {
+		F11 *Numeric `field:"11" length:"6" encode:"ascii"`
+		F59 *Lllvar  `field:"59" length:"999" encode:"ascii,ascii" content:"besteffort"`
+		F60 *Lllvar  `field:"60" length:"999" encode:"ascii,ascii" content:"bcd"`
+	}
+	b, err := NewMessage("0200", &data{
+		F11: NewNumeric("000001"),
+		F59: NewLllvar([]byte{'M', 0xFC, 'n', 'c', 'h', 'e', 'n'}),
+		F60: NewLllvar([]byte{0x12, 0x34}),
+	}).Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{}
+	assert.Nil(t, p.Register("0200", &data{}))
+	msg, err := p.Parse(b)
+	assert.Nil(t, err)
+	f := msg.Data.(*data).F59
+	assert.Equal(t, EncodingBestEffort, f.Encoding)
+	assert.Equal(t, "value is not valid UTF-8, decoded as Latin-1", f.Warning())
+	s, err := msg.GetString(59)
+	assert.Nil(t, err)
+	assert.Equal(t, "München", s)
+	j, err := json.Marshal(f)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"text":"München","hex":"4DFC6E6368656E","interpretation":"latin1"}`, string(j))
+	s, err = msg.GetString(60)
+	assert.Nil(t, err)
+	assert.Equal(t, "1234", s)
+
+	// an encoding set on the field is kept
+	f = &Lllvar{Encoding: EncodingUTF8}
+	loaded := NewMessage("", &data{F11: &Numeric{}, F59: f, F60: &Lllvar{}})
+	assert.Nil(t, loaded.Load(b))
+	assert.Equal(t, EncodingUTF8, f.Encoding)
+
+	type bad struct {
+		F59 *Lllvar `field:"59" length:"999" encode:"ascii,ascii" content:"ucs2"`
+	}
+	p = &Parser{}
+	assert.Nil(t, p.Register("0200", &bad{}))
+	_, err = p.Parse(b)
+	assert.NotNil(t, err)
+}
+
+func TestLllvarBestEffortMultibyte(t *testing.T) {
+	f := &Lllvar{Value: []byte("กรุงเทพ ไทย"), Encoding: EncodingBestEffort}
+	assert.Equal(t, EncodingUTF8, f.Interpretation())
+	assert.Equal(t, "กรุงเทพ ไทย", f.String())
+	assert.Equal(t, "", f.Warning())
+}
@@ -0,0 +1,345 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerFeature("continuation")
+}
+
+// ErrReassemblyTimeout is returned by Reassembler when the parts of a
+// message do not all arrive within the timeout of its rules
+var ErrReassemblyTimeout = errors.New("reassembly timed out")
+
+// ContinuationRules describe how a message too large for the network is
+// carried in several parts. The data field is split over the parts, and
+// each part holds its number and the total number of parts in the sequence
+// field, "0103" for the first of three with the default 2 digits each.
+type ContinuationRules struct {
+	// DataField is the Llvar, Lllvar or Llllvar field which is split, usually 120
+	DataField int
+
+	// SequenceField holds the part number and the total of a part
+	SequenceField int
+
+	// SequenceDigits is the number of digits of the part number and of the
+	// total in the sequence field, 2 when 0
+	SequenceDigits int
+
+	// KeyFields identify the message a part belongs to, such as 11 and 37
+	// (STAN and RRN), so that the parts of several messages can arrive
+	// interleaved. All parts belong to one message when it is empty.
+	KeyFields []int
+
+	// PartMti is the MTI of the parts, such as "0620". The MTI of the
+	// message is kept when it is empty.
+	PartMti string
+
+	// Mti is the MTI of the reassembled message. The MTI of the parts is
+	// kept when it is empty.
+	Mti string
+
+	// Timeout is how long after the first part the others may arrive.
+	// There is no limit when it is 0.
+	Timeout time.Duration
+}
+
+// Split returns msg in parts of at most maxSize bytes each. A message which
+// already fits is returned alone and unchanged. The data field may be
+// longer than its definition allows, as long as the parts are not.
+func Split(msg *Message, maxSize int, rules ContinuationRules) ([]*Message, error) {
+	if b, err := msg.Bytes(); err == nil && len(b) <= maxSize {
+		return []*Message{msg}, nil
+	}
+	f, err := msg.GetField(rules.DataField)
+	if err != nil {
+		return nil, err
+	}
+	data, err := continuationData(rules.DataField, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// the size of a part with one byte of data gives the room left for
+	// the data, length head included
+	probe, err := continuationPart(msg, rules, data[:1], 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := probe.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	room := maxSize - len(b) + 1
+	if room < 1 {
+		return nil, fmt.Errorf("no room for data field %d in %d bytes", rules.DataField, maxSize)
+	}
+	total := (len(data) + room - 1) / room
+	if max := rules.maxParts(); total > max {
+		return nil, fmt.Errorf("message needs %d parts, at most %d are allowed", total, max)
+	}
+
+	parts := make([]*Message, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * room
+		if end > len(data) {
+			end = len(data)
+		}
+		part, err := continuationPart(msg, rules, data[i*room:end], i+1, total)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+func (rules ContinuationRules) sequenceDigits() int {
+	if rules.SequenceDigits > 0 {
+		return rules.SequenceDigits
+	}
+	return 2
+}
+
+func (rules ContinuationRules) maxParts() int {
+	max := 1
+	for i := 0; i < rules.sequenceDigits(); i++ {
+		max *= 10
+	}
+	return max - 1
+}
+
+func continuationData(n int, f Iso8583Type) ([]byte, error) {
+	switch field := f.(type) {
+	case *Llvar:
+		return field.Value, nil
+	case *Lllvar:
+		return field.Value, nil
+	case *Llllvar:
+		return field.Value, nil
+	}
+	return nil, fmt.Errorf("field %d: unsupported type %T for continuation", n, f)
+}
+
+func continuationPart(msg *Message, rules ContinuationRules, data []byte, seq, total int) (*Message, error) {
+	part := msg.Clone()
+	if rules.PartMti != "" {
+		part.Mti = rules.PartMti
+	}
+	if err := setFieldString(part.Data, rules.DataField, string(data)); err != nil {
+		return nil, err
+	}
+	if err := setFieldString(part.Data, rules.SequenceField, rules.formatSequence(seq, total)); err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+// Reassembler joins the parts made by Split, which may arrive in any order.
+// The parts of several messages are told apart by the key fields of the
+// rules.
+type Reassembler struct {
+	Rules ContinuationRules
+
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+
+	pending map[string]*partialMessage
+}
+
+// partialMessage holds the parts of a message received so far
+type partialMessage struct {
+	key     string
+	first   *Message
+	parts   map[int][]byte
+	total   int
+	started time.Time
+}
+
+// NewReassembler returns a Reassembler for parts following rules
+func NewReassembler(rules ContinuationRules) *Reassembler {
+	return &Reassembler{Rules: rules}
+}
+
+// Add takes a part and returns the reassembled message once all parts have
+// arrived, or nil before that. A message without sequence field is
+// returned as is. A duplicate part is an error but keeps the parts received
+// so far, while a timeout or a part of another total starts the message
+// over. Only the message of part is checked for timeout; see Expire.
+func (r *Reassembler) Add(part *Message) (*Message, error) {
+	f, err := part.GetField(r.Rules.SequenceField)
+	if errors.Is(err, ErrFieldNotSet) {
+		return part, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seq, total, err := r.Rules.parseSequence(fieldString(f))
+	if err != nil {
+		return nil, err
+	}
+	f, err = part.GetField(r.Rules.DataField)
+	if err != nil {
+		return nil, err
+	}
+	data, err := continuationData(r.Rules.DataField, f)
+	if err != nil {
+		return nil, err
+	}
+	key, err := r.key(part)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	p := r.pending[key]
+	if p != nil && r.expired(p, now) {
+		delete(r.pending, key)
+		return nil, p.timeoutError()
+	}
+	if p == nil {
+		p = &partialMessage{key: key, parts: make(map[int][]byte, total), total: total, started: now}
+		if r.pending == nil {
+			r.pending = make(map[string]*partialMessage)
+		}
+		r.pending[key] = p
+	}
+	if total != p.total {
+		delete(r.pending, key)
+		return nil, fmt.Errorf("part %d: total %d, expected %d", seq, total, p.total)
+	}
+	if _, ok := p.parts[seq]; ok {
+		return nil, fmt.Errorf("duplicate part %d", seq)
+	}
+	p.parts[seq] = copyBytes(data)
+	if seq == 1 {
+		p.first = part
+	}
+	if len(p.parts) < p.total {
+		return nil, nil
+	}
+
+	var joined []byte
+	for i := 1; i <= p.total; i++ {
+		joined = append(joined, p.parts[i]...)
+	}
+	delete(r.pending, key)
+	msg := p.first.Clone()
+	if r.Rules.Mti != "" {
+		msg.Mti = r.Rules.Mti
+	}
+	if err := setFieldString(msg.Data, r.Rules.DataField, string(joined)); err != nil {
+		return nil, err
+	}
+	if err := setFieldString(msg.Data, r.Rules.SequenceField, ""); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Expire drops the messages whose parts have not all arrived within the
+// timeout of the rules, and returns an error wrapping ErrReassemblyTimeout
+// for each. Add only checks the message of the part it takes, so Expire
+// should be called periodically to free messages whose last parts never
+// come.
+func (r *Reassembler) Expire() []error {
+	now := r.now()
+	keys := make([]string, 0, len(r.pending))
+	for key, p := range r.pending {
+		if r.expired(p, now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		errs = append(errs, r.pending[key].timeoutError())
+		delete(r.pending, key)
+	}
+	return errs
+}
+
+// Missing returns the numbers of the parts not received yet of the message
+// part belongs to, in ascending order
+func (r *Reassembler) Missing(part *Message) []int {
+	key, err := r.key(part)
+	if err != nil {
+		return nil
+	}
+	if p := r.pending[key]; p != nil {
+		return p.missing()
+	}
+	return nil
+}
+
+// Pending returns the number of messages of which some parts have arrived
+func (r *Reassembler) Pending() int {
+	return len(r.pending)
+}
+
+func (r *Reassembler) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+func (r *Reassembler) expired(p *partialMessage, now time.Time) bool {
+	return r.Rules.Timeout > 0 && now.Sub(p.started) > r.Rules.Timeout
+}
+
+// key returns the values of the key fields of part, as "11=000001,37=ABC"
+func (r *Reassembler) key(part *Message) (string, error) {
+	values := make([]string, 0, len(r.Rules.KeyFields))
+	for _, n := range r.Rules.KeyFields {
+		f, err := part.GetField(n)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, fmt.Sprintf("%d=%s", n, fieldString(f)))
+	}
+	return strings.Join(values, ","), nil
+}
+
+func (p *partialMessage) missing() []int {
+	var ret []int
+	for i := 1; i <= p.total; i++ {
+		if _, ok := p.parts[i]; !ok {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+func (p *partialMessage) timeoutError() error {
+	err := fmt.Errorf("parts %v missing: %w", p.missing(), ErrReassemblyTimeout)
+	if p.key != "" {
+		err = fmt.Errorf("message %s: %w", p.key, err)
+	}
+	return err
+}
+
+func (rules ContinuationRules) formatSequence(seq, total int) string {
+	d := rules.sequenceDigits()
+	return fmt.Sprintf("%0*d%0*d", d, seq, d, total)
+}
+
+func (rules ContinuationRules) parseSequence(s string) (seq, total int, err error) {
+	d := rules.sequenceDigits()
+	if len(s) != 2*d {
+		return 0, 0, fmt.Errorf("invalid part sequence %q", s)
+	}
+	seq, err1 := strconv.Atoi(s[:d])
+	total, err2 := strconv.Atoi(s[d:])
+	if err1 != nil || err2 != nil || total < 1 || seq < 1 || seq > total {
+		return 0, 0, fmt.Errorf("invalid part sequence %q", s)
+	}
+	return seq, total, nil
+}
@@ -0,0 +1,200 @@
+package iso8583
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type continuationData120 struct {
+	F11  *Numeric `field:"11" length:"6" encode:"ascii"`
+	F71  *Numeric `field:"71" length:"4" encode:"ascii"`
+	F120 *Lllvar  `field:"120" length:"999" encode:"ascii,ascii"`
+}
+
+var testContinuationRules = ContinuationRules{
+	DataField:     120,
+	SequenceField: 71,
+	PartMti:       "0620",
+	Mti:           "0600",
+	Timeout:       time.Minute,
+}
+
+func newContinuationMessage(data string) *Message {
+	return newContinuationMessageStan(data, "000001")
+}
+
+func newContinuationMessageStan(data, stan string) *Message {
+	msg := NewMessage("0600", &continuationData120{
+		F11:  NewNumeric(stan),
+		F120: NewLllvar([]byte(data)),
+	})
+	msg.SecondBitmap = true
+	return msg
+}
+
+func TestSplitSinglePart(t *testing.T) {
+	msg := newContinuationMessage("short")
+	parts, err := Split(msg, 800, testContinuationRules)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Message{msg}, parts)
+
+	got, err := NewReassembler(testContinuationRules).Add(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, got)
+}
+
+func TestSplitReassemble(t *testing.T) {
+	data := strings.Repeat("0123456789", 200)
+	parts, err := Split(newContinuationMessage(data), 800, testContinuationRules)
+	assert.NoError(t, err)
+	assert.Len(t, parts, 3)
+	for i, part := range parts {
+		b, err := part.Bytes()
+		assert.NoError(t, err)
+		assert.True(t, len(b) <= 800)
+		assert.Equal(t, "0620", part.Mti)
+		assert.Equal(t, "0"+string(rune('1'+i))+"03", part.Data.(*continuationData120).F71.Value)
+	}
+
+	r := NewReassembler(testContinuationRules)
+	for _, i := range []int{2, 0} {
+		msg, err := r.Add(parts[i])
+		assert.NoError(t, err)
+		assert.Nil(t, msg)
+	}
+	msg, err := r.Add(parts[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "0600", msg.Mti)
+	assert.Equal(t, data, string(msg.Data.(*continuationData120).F120.Value))
+	assert.True(t, msg.Data.(*continuationData120).F71.IsEmpty())
+}
+
+func TestReassembleMissingPart(t *testing.T) {
+	parts, err := Split(newContinuationMessage(strings.Repeat("x", 2000)), 800, testContinuationRules)
+	assert.NoError(t, err)
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewReassembler(testContinuationRules)
+	r.Now = func() time.Time { return now }
+	for _, i := range []int{0, 2} {
+		msg, err := r.Add(parts[i])
+		assert.NoError(t, err)
+		assert.Nil(t, msg)
+	}
+	assert.Equal(t, []int{2}, r.Missing(parts[0]))
+
+	now = now.Add(2 * time.Minute)
+	_, err = r.Add(parts[1])
+	assert.True(t, errors.Is(err, ErrReassemblyTimeout))
+	assert.EqualError(t, err, "parts [2] missing: reassembly timed out")
+	assert.Nil(t, r.Missing(parts[0]))
+}
+
+func TestReassembleDuplicatePart(t *testing.T) {
+	parts, err := Split(newContinuationMessage(strings.Repeat("x", 2000)), 800, testContinuationRules)
+	assert.NoError(t, err)
+
+	r := NewReassembler(testContinuationRules)
+	_, err = r.Add(parts[0])
+	assert.NoError(t, err)
+	_, err = r.Add(parts[0])
+	assert.EqualError(t, err, "duplicate part 1")
+	assert.Equal(t, []int{2, 3}, r.Missing(parts[0]))
+}
+
+func TestReassembleInterleaved(t *testing.T) {
+	rules := testContinuationRules
+	rules.KeyFields = []int{11}
+	a, b := strings.Repeat("a", 2000), strings.Repeat("b", 2000)
+	partsA, err := Split(newContinuationMessageStan(a, "000001"), 800, rules)
+	assert.NoError(t, err)
+	partsB, err := Split(newContinuationMessageStan(b, "000002"), 800, rules)
+	assert.NoError(t, err)
+
+	r := NewReassembler(rules)
+	for i := 0; i < 2; i++ {
+		for _, part := range []*Message{partsA[i], partsB[i]} {
+			msg, err := r.Add(part)
+			assert.NoError(t, err)
+			assert.Nil(t, msg)
+		}
+	}
+	assert.Equal(t, 2, r.Pending())
+	assert.Equal(t, []int{3}, r.Missing(partsB[0]))
+
+	msg, err := r.Add(partsB[2])
+	assert.NoError(t, err)
+	assert.Equal(t, b, string(msg.Data.(*continuationData120).F120.Value))
+	msg, err = r.Add(partsA[2])
+	assert.NoError(t, err)
+	assert.Equal(t, a, string(msg.Data.(*continuationData120).F120.Value))
+	assert.Equal(t, 0, r.Pending())
+
+	// a part without key field
+	partsA[0].Data.(*continuationData120).F11 = nil
+	_, err = r.Add(partsA[0])
+	assert.EqualError(t, err, "field 11: field not set")
+}
+
+func TestReassembleExpire(t *testing.T) {
+	rules := testContinuationRules
+	rules.KeyFields = []int{11}
+	partsA, err := Split(newContinuationMessageStan(strings.Repeat("a", 2000), "000001"), 800, rules)
+	assert.NoError(t, err)
+	partsB, err := Split(newContinuationMessageStan(strings.Repeat("b", 2000), "000002"), 800, rules)
+	assert.NoError(t, err)
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewReassembler(rules)
+	r.Now = func() time.Time { return now }
+	_, err = r.Add(partsA[0])
+	assert.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, err = r.Add(partsB[1])
+	assert.NoError(t, err)
+	assert.Nil(t, r.Expire())
+
+	now = now.Add(45 * time.Second)
+	errs := r.Expire()
+	assert.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], ErrReassemblyTimeout))
+	assert.EqualError(t, errs[0], "message 11=000001: parts [2 3] missing: reassembly timed out")
+	assert.Equal(t, 1, r.Pending())
+	assert.Nil(t, r.Missing(partsA[0]))
+	assert.Equal(t, []int{1, 3}, r.Missing(partsB[0]))
+}
+
+func TestSplitSequenceDigits(t *testing.T) {
+	type data struct {
+		F71  *Numeric `field:"71" length:"6" encode:"ascii"`
+		F120 *Lllvar  `field:"120" length:"999" encode:"ascii,ascii"`
+	}
+	rules := testContinuationRules
+	rules.SequenceDigits = 3
+	text := strings.Repeat("x", 2000)
+	msg := NewMessage("0600", &data{F120: NewLllvar([]byte(text))})
+	msg.SecondBitmap = true
+	parts, err := Split(msg, 800, rules)
+	assert.NoError(t, err)
+	assert.Len(t, parts, 3)
+	assert.Equal(t, "002003", parts[1].Data.(*data).F71.Value)
+
+	r := NewReassembler(rules)
+	for _, part := range parts {
+		msg, err = r.Add(part)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, text, string(msg.Data.(*data).F120.Value))
+
+	_, err = NewReassembler(testContinuationRules).Add(parts[0])
+	assert.EqualError(t, err, `invalid part sequence "001003"`)
+
+	rules.SequenceDigits = 1
+	msg.Data.(*data).F120 = NewLllvar([]byte(strings.Repeat("x", 9999)))
+	_, err = Split(msg, 800, rules)
+	assert.EqualError(t, err, "message needs 13 parts, at most 9 are allowed")
+}
@@ -0,0 +1,106 @@
+package iso8583
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Describe returns a table of the fields defined by the tags of data, a
+// struct or pointer to struct used as message template. Fields are listed
+// in ascending order.
+func Describe(data interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := DescribeTo(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// DescribeTo writes the table returned by Describe to w
+func DescribeTo(w io.Writer, data interface{}) error {
+	t := reflect.TypeOf(data)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("data must be a struct")
+	}
+
+	type row struct {
+		index                                 int
+		typeName, encoder, lenEncoder, length string
+	}
+	var rows []row
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		index, err := strconv.Atoi(sf.Tag.Get(TAG_FIELD))
+		if err != nil {
+			continue
+		}
+		r := row{index: index, encoder: "ascii", lenEncoder: "-", length: "-"}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		r.typeName = ft.Name()
+		if isVariableType(ft) {
+			r.lenEncoder = "ascii"
+		}
+		if raw := sf.Tag.Get(TAG_ENCODE); raw != "" {
+			enc := strings.Split(raw, ",")
+			if len(enc) == 2 {
+				r.lenEncoder = encodeName(parseEncodeStr(enc[0]))
+				r.encoder = encodeName(parseEncodeStr(enc[1]))
+			} else {
+				r.encoder = encodeName(parseEncodeStr(enc[0]))
+			}
+		}
+		if l := sf.Tag.Get(TAG_LENGTH); l != "" {
+			r.length = l
+		}
+		rows = append(rows, r)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].index < rows[j].index })
+
+	if _, err := fmt.Fprintf(w, "%5s  %-14s %-9s %-11s %6s\n", "FIELD", "TYPE", "ENCODER", "LEN ENCODER", "LENGTH"); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if _, err := fmt.Fprintf(w, "%5d  %-14s %-9s %-11s %6s\n", r.index, r.typeName, r.encoder, r.lenEncoder, r.length); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isVariableType reports whether t is one of the variable length field
+// types of the package, all of which are named after their length head
+func isVariableType(t reflect.Type) bool {
+	return t.PkgPath() == reflect.TypeOf(Llvar{}).PkgPath() && strings.HasPrefix(t.Name(), "Ll")
+}
+
+// encodeName is the tag name of an encoder, the reverse of parseEncodeStr
+func encodeName(encode int) string {
+	switch encode {
+	case ASCII:
+		return "ascii"
+	case BCD:
+		return "bcd"
+	case rBCD:
+		return "rbcd"
+	case BinaryLen2:
+		return "binlen2"
+	case BinaryLen2LE:
+		return "binlen2le"
+	case ZoneBCD:
+		return "zbcd"
+	case EBCDIC:
+		return "ebcdic"
+	}
+	return "invalid"
+}
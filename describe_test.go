@@ -0,0 +1,35 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	type data struct {
+		F52 *Binary       `field:"52" length:"8"`
+		F2  *Llnumeric    `field:"2" length:"19" encode:"bcd,rbcd"`
+		F3  *Numeric      `field:"3" length:"6" encode:"bcd"`
+		F41 *Alphanumeric `field:"41" length:"8"`
+		F55 *Llvar        `field:"55" encode:"binlen2,ascii"`
+		F60 *Lllvar       `field:"60" length:"999"`
+		F61 *Llalpha      `field:"61" length:"25" encode:"ebcdic"`
+		Foo string
+	}
+
+	out, err := Describe(&data{})
+	assert.Nil(t, err)
+	assert.Equal(t, ""+
+		"FIELD  TYPE           ENCODER   LEN ENCODER LENGTH\n"+
+		"    2  Llnumeric      rbcd      bcd             19\n"+
+		"    3  Numeric        bcd       -                6\n"+
+		"   41  Alphanumeric   ascii     -                8\n"+
+		"   52  Binary         ascii     -                8\n"+
+		"   55  Llvar          ascii     binlen2          -\n"+
+		"   60  Lllvar         ascii     ascii          999\n"+
+		"   61  Llalpha        ebcdic    ascii           25\n", out)
+
+	_, err = Describe(nil)
+	assert.EqualError(t, err, "data must be a struct")
+}
@@ -0,0 +1,79 @@
+package iso8583
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func init() {
+	registerFeature("ebcdic")
+}
+
+// asciiToEBCDIC maps the printable ASCII characters, from space (0x20) to
+// '~' (0x7E), to EBCDIC code page 037
+var asciiToEBCDIC = [95]byte{
+	0x40, 0x5A, 0x7F, 0x7B, 0x5B, 0x6C, 0x50, 0x7D, // space ! " # $ % & '
+	0x4D, 0x5D, 0x5C, 0x4E, 0x6B, 0x60, 0x4B, 0x61, // ( ) * + , - . /
+	0xF0, 0xF1, 0xF2, 0xF3, 0xF4, 0xF5, 0xF6, 0xF7, // 0-7
+	0xF8, 0xF9, 0x7A, 0x5E, 0x4C, 0x7E, 0x6E, 0x6F, // 8 9 : ; < = > ?
+	0x7C, 0xC1, 0xC2, 0xC3, 0xC4, 0xC5, 0xC6, 0xC7, // @ A-G
+	0xC8, 0xC9, 0xD1, 0xD2, 0xD3, 0xD4, 0xD5, 0xD6, // H-O
+	0xD7, 0xD8, 0xD9, 0xE2, 0xE3, 0xE4, 0xE5, 0xE6, // P-W
+	0xE7, 0xE8, 0xE9, 0xBA, 0xE0, 0xBB, 0xB0, 0x6D, // X Y Z [ \ ] ^ _
+	0x79, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, // ` a-g
+	0x88, 0x89, 0x91, 0x92, 0x93, 0x94, 0x95, 0x96, // h-o
+	0x97, 0x98, 0x99, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, // p-w
+	0xA7, 0xA8, 0xA9, 0xC0, 0x4F, 0xD0, 0xA1, // x y z { | } ~
+}
+
+// ebcdicToASCII is the reverse of asciiToEBCDIC, 0 for bytes without a
+// printable ASCII character
+var ebcdicToASCII [256]byte
+
+func init() {
+	for i, b := range asciiToEBCDIC {
+		ebcdicToASCII[b] = byte(i) + ' '
+	}
+}
+
+func ebcdicEncode(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, c := range data {
+		if c < ' ' || c > '~' {
+			return nil, fmt.Errorf("%w: character %q at %d has no EBCDIC equivalent", &ErrInvalidEncoder{EBCDIC}, c, i)
+		}
+		out[i] = asciiToEBCDIC[c-' ']
+	}
+	return out, nil
+}
+
+func ebcdicDecode(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		c := ebcdicToASCII[b]
+		if c == 0 {
+			return nil, fmt.Errorf("%w: EBCDIC byte 0x%02x at %d has no ASCII equivalent", &ErrInvalidEncoder{EBCDIC}, b, i)
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+// ebcdicDigits encodes a length head, which only holds digits
+func ebcdicDigits(digits []byte) []byte {
+	out, _ := ebcdicEncode(digits)
+	return out
+}
+
+// ebcdicLength decodes a length head of EBCDIC digits
+func ebcdicLength(raw []byte) (int, error) {
+	digits, err := ebcdicDecode(raw)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(string(digits))
+	if err != nil {
+		return 0, &ErrParseLength{string(digits)}
+	}
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEBCDICPrintableASCII(t *testing.T) {
+	var printable []byte
+	for c := byte(' '); c <= '~'; c++ {
+		printable = append(printable, c)
+	}
+	encoded, err := ebcdicEncode(printable)
+	assert.Nil(t, err)
+	assert.Len(t, encoded, len(printable))
+
+	// every character has its own code
+	seen := make(map[byte]byte)
+	for i, b := range encoded {
+		prev, ok := seen[b]
+		assert.False(t, ok, "0x%02x is used by %q and %q", b, prev, printable[i])
+		seen[b] = printable[i]
+	}
+
+	decoded, err := ebcdicDecode(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, printable, decoded)
+
+	b, err := ebcdicEncode([]byte("Az09 ~{"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xC1, 0xA9, 0xF0, 0xF9, 0x40, 0xA1, 0xC0}, b)
+}
+
+func TestEBCDICInvalid(t *testing.T) {
+	_, err := ebcdicEncode([]byte("ab\ncd"))
+	assert.EqualError(t, err, `invalid encoder: character '\n' at 2 has no EBCDIC equivalent`)
+	_, err = ebcdicEncode([]byte{'a', 0xE9})
+	assert.EqualError(t, err, `invalid encoder: character 'é' at 1 has no EBCDIC equivalent`)
+	_, err = ebcdicDecode([]byte{0xC1, 0xFF})
+	assert.EqualError(t, err, "invalid encoder: EBCDIC byte 0xff at 1 has no ASCII equivalent")
+
+	_, err = NewAlphanumeric("a\tb").Bytes(EBCDIC, 0, 3)
+	assert.NotNil(t, err)
+	_, err = (&Llvar{}).Load([]byte{0xF0, 0xF2, 0x00, 0x00}, EBCDIC, EBCDIC, 99)
+	assert.EqualError(t, err, "invalid encoder: EBCDIC byte 0x00 at 0 has no ASCII equivalent")
+	_, err = (&Llnumeric{}).Load([]byte{0xF0}, EBCDIC, EBCDIC, 99)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+}
+
+func TestMessageEBCDIC(t *testing.T) {
+	type data struct {
+		F2  *Llnumeric    `field:"2" length:"19" encode:"ebcdic,ebcdic"`
+		F3  *Numeric      `field:"3" length:"6" encode:"ebcdic"`
+		F41 *Alphanumeric `field:"41" length:"8" encode:"ebcdic"`
+		F44 *Llvar        `field:"44" length:"25" encode:"ebcdic,ebcdic"`
+		F48 *Lllvar       `field:"48" length:"999" encode:"bcd,ebcdic"`
+		F62 *Lllnumeric   `field:"62" length:"999" encode:"ebcdic,ebcdic"`
+	}
+	msg := NewMessage("0200", &data{
+		F2:  NewLlnumeric("4111111111111111"),
+		F3:  NewNumeric("3000"),
+		F41: NewAlphanumeric("Term-01"),
+		F44: NewLlvar([]byte("Resp: ok!")),
+		F48: NewLllvar([]byte("a=b;c=d")),
+		F62: NewLllnumeric("12345"),
+	})
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	// the length head and first digits of field 2
+	assert.Equal(t, []byte{0xF1, 0xF6, 0xF4, 0xF1}, b[12:16])
+
+	parsed := NewMessage("", &data{
+		F2: &Llnumeric{}, F3: &Numeric{}, F41: &Alphanumeric{},
+		F44: &Llvar{}, F48: &Lllvar{}, F62: &Lllnumeric{},
+	})
+	assert.Nil(t, parsed.Load(b))
+	d := parsed.Data.(*data)
+	assert.Equal(t, "4111111111111111", d.F2.Value)
+	assert.Equal(t, "003000", d.F3.Value)
+	assert.Equal(t, " Term-01", d.F41.Value)
+	assert.Equal(t, "Resp: ok!", string(d.F44.Value))
+	assert.Equal(t, "a=b;c=d", string(d.F48.Value))
+	assert.Equal(t, "12345", d.F62.Value)
+
+	again, err := parsed.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, b, again)
+}
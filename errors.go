@@ -0,0 +1,195 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerFeature("typed_errors")
+}
+
+// EncodeError wraps every error returned by Message.Bytes: the message
+// being built is invalid. The message of the wrapped error is kept.
+type EncodeError struct {
+	Err error
+}
+
+func (e *EncodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError wraps every error returned by Message.Load and Parser.Parse:
+// the received bytes are invalid. The message of the wrapped error is kept.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func encodeError(err error) error {
+	var e *EncodeError
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return &EncodeError{err}
+}
+
+func decodeError(err error) error {
+	var e *DecodeError
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return &DecodeError{err}
+}
+
+// ErrInvalidEncoder is returned when a field does not support its encoder
+type ErrInvalidEncoder struct {
+	Encoder int
+}
+
+func (e *ErrInvalidEncoder) Error() string {
+	return ERR_INVALID_ENCODER
+}
+
+// ErrInvalidLengthEncoder is returned when a field does not support its
+// length encoder
+type ErrInvalidLengthEncoder struct {
+	Encoder int
+}
+
+func (e *ErrInvalidLengthEncoder) Error() string {
+	return ERR_INVALID_LENGTH_ENCODER
+}
+
+// ErrInvalidLengthHead is returned when the length of a value does not fit
+// into the length head of its field
+type ErrInvalidLengthHead struct {
+	// TypeName is the name of the field type
+	TypeName string
+	// MaxLen is the largest length the head can hold
+	MaxLen    int
+	ActualLen int
+}
+
+func (e *ErrInvalidLengthHead) Error() string {
+	return ERR_INVALID_LENGTH_HEAD
+}
+
+// ErrMissingLength is returned for a fixed length field without a length
+type ErrMissingLength struct{}
+
+func (e *ErrMissingLength) Error() string {
+	return ERR_MISSING_LENGTH
+}
+
+// ErrValueTooLong is returned when a value is longer than the length of its
+// field
+type ErrValueTooLong struct {
+	// TypeName is the name of the field type
+	TypeName   string
+	DefinedLen int
+	ActualLen  int
+}
+
+func (e *ErrValueTooLong) Error() string {
+	return fmt.Sprintf(ERR_VALUE_TOO_LONG, e.TypeName, e.DefinedLen, e.ActualLen)
+}
+
+// ErrBadRaw is returned by Load when the raw data is too short for the
+// field
+type ErrBadRaw struct {
+	// TypeName is the name of the field type
+	TypeName string
+	// Needed is the number of bytes the field needs, Available the number
+	// left in the raw data
+	Needed    int
+	Available int
+}
+
+func (e *ErrBadRaw) Error() string {
+	return ERR_BAD_RAW
+}
+
+// ErrParseLength is returned when a length head is not a number
+type ErrParseLength struct {
+	// Head is the length head as read
+	Head string
+}
+
+func (e *ErrParseLength) Error() string {
+	return ERR_PARSE_LENGTH_FAILED + ": " + e.Head
+}
+
+// ErrValueNotAllowed is returned when a value is not one of the values
+// allowed for its field
+type ErrValueNotAllowed struct {
+	// TypeName is the name of the field type
+	TypeName string
+	Value    string
+}
+
+func (e *ErrValueNotAllowed) Error() string {
+	return fmt.Sprintf(ERR_VALUE_NOT_ALLOWED, e.TypeName, e.Value)
+}
+
+// FieldSize is the encoded size of a field, length head included
+type FieldSize struct {
+	Field int
+	Size  int
+}
+
+// ErrMessageTooLarge is returned by Message.Bytes when the message is
+// larger than MaxMessageSize
+type ErrMessageTooLarge struct {
+	Size  int
+	Limit int
+	// Largest are the 5 largest fields, largest first
+	Largest []FieldSize
+}
+
+func (e *ErrMessageTooLarge) Error() string {
+	fields := make([]string, len(e.Largest))
+	for i, f := range e.Largest {
+		fields[i] = fmt.Sprintf("%d (%d)", f.Field, f.Size)
+	}
+	msg := fmt.Sprintf("message is %d bytes, limit is %d", e.Size, e.Limit)
+	if len(fields) > 0 {
+		msg += "; largest fields: " + strings.Join(fields, ", ")
+	}
+	return msg
+}
+
+// messageTooLarge returns the error for an encoded message of size bytes
+// whose parts are placed as given by extents
+func messageTooLarge(size, limit int, extents map[int]extent) error {
+	var largest []FieldSize
+	for n, e := range extents {
+		// 0 and 1 are the MTI and the bitmap
+		if n > 1 {
+			largest = append(largest, FieldSize{n, e.end - e.start})
+		}
+	}
+	sort.Slice(largest, func(i, j int) bool {
+		if largest[i].Size != largest[j].Size {
+			return largest[i].Size > largest[j].Size
+		}
+		return largest[i].Field < largest[j].Field
+	})
+	if len(largest) > 5 {
+		largest = largest[:5]
+	}
+	return &ErrMessageTooLarge{Size: size, Limit: limit, Largest: largest}
+}
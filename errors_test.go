@@ -0,0 +1,166 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeErrors(t *testing.T) {
+	var encErr *EncodeError
+	var decErr *DecodeError
+
+	// encode side
+	_, err := NewMessage("0200", &TestISO{F3: NewNumeric("1234567")}).Bytes()
+	assert.True(t, errors.As(err, &encErr))
+	assert.False(t, errors.As(err, &decErr))
+	assert.EqualError(t, err, "length of value is longer than definition; type=Numeric, def_len=6, len=7")
+
+	_, err = NewMessage("20", &TestISO{}).Bytes()
+	assert.True(t, errors.As(err, &encErr))
+
+	_, err = NewMessage("0200", "not a struct").Bytes()
+	assert.True(t, errors.As(err, &encErr))
+	assert.EqualError(t, err, "Critical error:data must be a struct")
+
+	// decode side
+	raw, err := NewMessage("0200", &TestISO{F2: NewLlnumeric("4276555555555555")}).Bytes()
+	assert.Nil(t, err)
+
+	err = NewMessage("", &TestISO{F2: NewLlnumeric("")}).Load(raw[:len(raw)-4])
+	assert.True(t, errors.As(err, &decErr))
+	assert.False(t, errors.As(err, &encErr))
+
+	bad := append([]byte(nil), raw...)
+	bad[12] = 0x7b
+	err = NewMessage("", &TestISO{F2: NewLlnumeric("")}).Load(bad)
+	assert.True(t, errors.As(err, &decErr))
+
+	parser := &Parser{}
+	assert.Nil(t, parser.Register("0200", &TestISO{}))
+	_, err = parser.Parse([]byte("0800"))
+	assert.True(t, errors.As(err, &decErr))
+	assert.EqualError(t, err, "no template registered for MTI: 0800")
+
+	// wrapping keeps the inner error reachable, and is not repeated
+	_, err = (&Numeric{Value: "1"}).Add(-2)
+	assert.True(t, errors.Is(&EncodeError{err}, ErrNegativeAmount))
+	assert.Equal(t, decodeError(err), decodeError(decodeError(err)))
+
+	// typed errors of the fields are reachable through the wrapper
+	var bcdErr *ErrInvalidBCDLength
+	type data struct {
+		F2 *Llnumeric `field:"2" length:"19" encode:"bcd,ascii"`
+	}
+	err = NewMessage("", &data{F2: NewLlnumeric("")}).Load(bad)
+	assert.True(t, errors.As(err, &decErr))
+	assert.True(t, errors.As(err, &bcdErr))
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	type data struct {
+		F2  *Llnumeric `field:"2" length:"19" encode:"ascii,ascii"`
+		F3  *Numeric   `field:"3" length:"6" encode:"ascii"`
+		F4  *Numeric   `field:"4" length:"12" encode:"ascii"`
+		F11 *Numeric   `field:"11" length:"6" encode:"ascii"`
+		F41 *Binary    `field:"41" length:"8"`
+		F49 *Numeric   `field:"49" length:"3" encode:"ascii"`
+		F55 *Lllvar    `field:"55" length:"999" encode:"ascii,ascii"`
+	}
+	d := &data{
+		F2:  NewLlnumeric("4111111111111111"),
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000001000"),
+		F11: NewNumeric("000001"),
+		F41: NewBinary([]byte("TERM0001")),
+		F49: NewNumeric("764"),
+		F55: NewLllvar(make([]byte, 100)),
+	}
+	msg := NewMessage("0200", d)
+	msg.MaxMessageSize = 200
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Len(t, b, 4+8+18+6+12+6+8+3+103)
+
+	d.F55.Value = make([]byte, 900)
+	b, err = msg.Bytes()
+	assert.Nil(t, b)
+	var tooLarge *ErrMessageTooLarge
+	assert.True(t, errors.As(err, &tooLarge))
+	var encodeErr *EncodeError
+	assert.True(t, errors.As(err, &encodeErr))
+	assert.Equal(t, 968, tooLarge.Size)
+	assert.Equal(t, 200, tooLarge.Limit)
+	assert.Equal(t, []FieldSize{{55, 903}, {2, 18}, {4, 12}, {41, 8}, {3, 6}}, tooLarge.Largest)
+	assert.EqualError(t, err, "message is 968 bytes, limit is 200; largest fields: 55 (903), 2 (18), 4 (12), 41 (8), 3 (6)")
+
+	// the sizes are known when an Encoder places the parts
+	msg.Encoder = bitmapFirst{}
+	msg.MaxMessageSize = 200
+	_, ext, err := msg.BytesWithExtents()
+	assert.Equal(t, Extents{}, ext)
+	if assert.True(t, errors.As(err, &tooLarge)) {
+		assert.Equal(t, 968, tooLarge.Size)
+		assert.Equal(t, []FieldSize{{55, 903}, {2, 18}, {4, 12}, {41, 8}, {3, 6}}, tooLarge.Largest)
+	}
+
+	msg.MaxMessageSize = 0
+	_, err = msg.Bytes()
+	assert.Nil(t, err)
+}
+
+func TestTypedErrors(t *testing.T) {
+	_, err := NewMessage("0200", &TestISO{F3: NewNumeric("1234567")}).Bytes()
+	var tooLong *ErrValueTooLong
+	if assert.True(t, errors.As(err, &tooLong)) {
+		assert.Equal(t, ErrValueTooLong{TypeName: "Numeric", DefinedLen: 6, ActualLen: 7}, *tooLong)
+	}
+	assert.EqualError(t, err, "length of value is longer than definition; type=Numeric, def_len=6, len=7")
+
+	_, err = NewLlvar([]byte("abc")).Bytes(ASCII, ZoneBCD, 10)
+	var lenEnc *ErrInvalidLengthEncoder
+	if assert.True(t, errors.As(err, &lenEnc)) {
+		assert.Equal(t, ZoneBCD, lenEnc.Encoder)
+	}
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_ENCODER)
+
+	_, err = (&Lllvar{}).Load([]byte("00x"), ASCII, ASCII, 999)
+	var parseLen *ErrParseLength
+	if assert.True(t, errors.As(err, &parseLen)) {
+		assert.Equal(t, "00x", parseLen.Head)
+	}
+
+	_, err = (&Numeric{}).Load([]byte("12"), ASCII, ASCII, 6)
+	var badRaw *ErrBadRaw
+	if assert.True(t, errors.As(err, &badRaw)) {
+		assert.Equal(t, "Numeric", badRaw.TypeName)
+		assert.Equal(t, 6, badRaw.Needed)
+		assert.Equal(t, 2, badRaw.Available)
+	}
+	assert.EqualError(t, err, ERR_BAD_RAW)
+
+	_, err = (&Llvar{}).Load([]byte("05ab"), ASCII, ASCII, 99)
+	assert.Equal(t, &ErrBadRaw{"Llvar", 7, 4}, err)
+
+	_, err = NewLlvar(make([]byte, 100)).Bytes(ASCII, ASCII, -1)
+	var head *ErrInvalidLengthHead
+	if assert.True(t, errors.As(err, &head)) {
+		assert.Equal(t, "Llvar", head.TypeName)
+		assert.Equal(t, 99, head.MaxLen)
+		assert.Equal(t, 100, head.ActualLen)
+	}
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_HEAD)
+
+	_, err = NewLllbinary(make([]byte, 1000)).Bytes(ASCII, ASCII, -1)
+	assert.Equal(t, &ErrInvalidLengthHead{"Lllbinary", 999, 1000}, err)
+
+	_, err = NewAlphanumeric("A").Bytes(EBCDIC, ASCII, 1)
+	assert.Nil(t, err)
+	_, err = NewAlphanumeric("\x01").Bytes(EBCDIC, ASCII, 1)
+	var enc *ErrInvalidEncoder
+	if assert.True(t, errors.As(err, &enc)) {
+		assert.Equal(t, EBCDIC, enc.Encoder)
+	}
+}
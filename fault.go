@@ -0,0 +1,97 @@
+package iso8583
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+func init() {
+	registerFeature("fault_injection")
+}
+
+// FaultInjector injects faults into encoding and decoding, for chaos
+// testing of the systems exchanging messages. It is meant for tests only:
+// production messages and parsers leave it unset, which costs a nil check.
+//
+// The byte slices passed may be views of field values, so they must not be
+// changed in place; a changed copy is returned instead.
+type FaultInjector interface {
+	// AfterField is called by Bytes with the encoded bytes of field n,
+	// length head included
+	AfterField(n int, b []byte) []byte
+	// AfterEncode is called by Bytes with the encoded message
+	AfterEncode(b []byte) []byte
+	// BeforeDecode is called by Load and Parse with the bytes to decode. It
+	// may block to simulate a slow peer.
+	BeforeDecode(b []byte) []byte
+}
+
+// RandomFaults is a FaultInjector injecting faults at random with the
+// given probabilities, from 0 to 1. The faults depend only on the seed and
+// the sequence of calls, so a failing run can be reproduced. It is safe
+// for concurrent use. The zero value is usable and seeded with 1, like the
+// top-level functions of math/rand.
+type RandomFaults struct {
+	// CorruptField is the probability that AfterField inverts the first
+	// byte of a field, which is the length head of variable length fields
+	CorruptField float64
+	// Truncate is the probability that AfterEncode cuts the message short
+	Truncate float64
+	// DelayProbability is the probability that BeforeDecode sleeps for Delay
+	DelayProbability float64
+	Delay            time.Duration
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRandomFaults create new RandomFaults seeded with seed, injecting no
+// faults until probabilities are set
+func NewRandomFaults(seed int64) *RandomFaults {
+	return &RandomFaults{rand: rand.New(rand.NewSource(seed))}
+}
+
+// hit reports whether a fault of probability p is injected, and a random
+// number below n for placing it
+func (f *RandomFaults) hit(p float64, n int) (bool, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rand == nil {
+		f.rand = rand.New(rand.NewSource(1))
+	}
+	if f.rand.Float64() >= p {
+		return false, 0
+	}
+	if n <= 1 {
+		return true, 0
+	}
+	return true, f.rand.Intn(n)
+}
+
+// AfterField inverts the first byte of b with probability CorruptField
+func (f *RandomFaults) AfterField(n int, b []byte) []byte {
+	if ok, _ := f.hit(f.CorruptField, 0); !ok || len(b) == 0 {
+		return b
+	}
+	c := copyBytes(b)
+	c[0] ^= 0xFF
+	return c
+}
+
+// AfterEncode truncates b at a random position with probability Truncate
+func (f *RandomFaults) AfterEncode(b []byte) []byte {
+	ok, at := f.hit(f.Truncate, len(b))
+	if !ok {
+		return b
+	}
+	return b[:at]
+}
+
+// BeforeDecode sleeps for Delay with probability DelayProbability
+func (f *RandomFaults) BeforeDecode(b []byte) []byte {
+	if ok, _ := f.hit(f.DelayProbability, 0); ok {
+		time.Sleep(f.Delay)
+	}
+	return b
+}
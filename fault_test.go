@@ -0,0 +1,151 @@
+package iso8583
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// truncateFaults cuts the bytes to decode to n bytes
+type truncateFaults struct {
+	n int
+}
+
+func (f truncateFaults) AfterField(n int, b []byte) []byte { return b }
+func (f truncateFaults) AfterEncode(b []byte) []byte       { return b }
+func (f truncateFaults) BeforeDecode(b []byte) []byte      { return b[:f.n] }
+
+func newFaultsMessage() *Message {
+	return NewMessage("0200", &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F11: NewNumeric("000001"),
+	})
+}
+
+func TestFaultsAfterField(t *testing.T) {
+	clean, err := newFaultsMessage().Bytes()
+	assert.Nil(t, err)
+
+	msg := newFaultsMessage()
+	faults := NewRandomFaults(1)
+	faults.CorruptField = 1
+	msg.Faults = faults
+	b, ext, err := msg.BytesWithExtents()
+	assert.Nil(t, err)
+	assert.Len(t, b, len(clean))
+	// the length head of field 2 and the first byte of fields 3 and 11
+	for _, n := range []int{2, 3, 11} {
+		start, _, err := ext.Extent(n, n)
+		assert.Nil(t, err)
+		assert.Equal(t, clean[start]^0xFF, b[start], "field %d", n)
+	}
+	// the field values are not changed
+	assert.Equal(t, "4276555555555555", msg.Data.(*TestISO).F2.Value)
+
+	p := &Parser{}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	_, err = p.Parse(b)
+	assert.NotNil(t, err)
+}
+
+func TestFaultsAfterEncode(t *testing.T) {
+	clean, err := newFaultsMessage().Bytes()
+	assert.Nil(t, err)
+
+	msg := newFaultsMessage()
+	faults := NewRandomFaults(1)
+	faults.Truncate = 1
+	msg.Faults = faults
+	b, ext, err := msg.BytesWithExtents()
+	assert.Nil(t, err)
+	assert.True(t, len(b) < len(clean))
+	assert.Equal(t, clean[:len(b)], b)
+	_, _, err = ext.Extent(2, 11)
+	assert.NotNil(t, err)
+}
+
+func TestFaultsBeforeDecode(t *testing.T) {
+	b, err := newFaultsMessage().Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{Faults: truncateFaults{len(b) - 1}}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	_, err = p.Parse(b)
+	assert.NotNil(t, err)
+
+	msg := NewMessage("", &TestISO{})
+	msg.Faults = truncateFaults{3}
+	assert.NotNil(t, msg.Load(b))
+
+	faults := NewRandomFaults(1)
+	faults.DelayProbability = 1
+	faults.Delay = 20 * time.Millisecond
+	p.Faults = faults
+	start := time.Now()
+	_, err = p.Parse(b)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) >= faults.Delay)
+}
+
+func TestFaultsRetainRaw(t *testing.T) {
+	b, err := newFaultsMessage().Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{Faults: truncateFaults{len(b) - 1}, RetainRaw: true}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	msg, err := p.Parse(b)
+	assert.NotNil(t, err)
+	assert.True(t, msg.RetainRaw)
+	assert.Equal(t, b, msg.Raw())
+
+	msg = NewMessage("", &TestISO{})
+	msg.RetainRaw = true
+	msg.Faults = truncateFaults{3}
+	assert.NotNil(t, msg.Load(b))
+	assert.Equal(t, b, msg.Raw())
+}
+
+func TestRandomFaultsSeed(t *testing.T) {
+	run := func(seed int64) [][]byte {
+		faults := NewRandomFaults(seed)
+		faults.CorruptField = 0.3
+		faults.Truncate = 0.3
+		var out [][]byte
+		for i := 0; i < 20; i++ {
+			msg := newFaultsMessage()
+			msg.Faults = faults
+			b, err := msg.Bytes()
+			assert.Nil(t, err)
+			out = append(out, b)
+		}
+		return out
+	}
+	assert.Equal(t, run(7), run(7))
+	assert.NotEqual(t, run(7), run(8))
+
+	// the zero value is seeded with 1
+	lengths := func(faults *RandomFaults) []int {
+		faults.Truncate = 0.5
+		msg := newFaultsMessage()
+		msg.Faults = faults
+		var out []int
+		for i := 0; i < 5; i++ {
+			b, err := msg.Bytes()
+			assert.Nil(t, err)
+			out = append(out, len(b))
+		}
+		return out
+	}
+	assert.Equal(t, lengths(NewRandomFaults(1)), lengths(&RandomFaults{}))
+
+	// no faults until probabilities are set
+	msg := newFaultsMessage()
+	msg.Faults = NewRandomFaults(7)
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	clean, err := newFaultsMessage().Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, clean, b)
+}
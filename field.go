@@ -1,13 +1,28 @@
 package iso8583
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"unicode/utf8"
 )
 
+func init() {
+	registerFeature("binary_length_head")
+	registerFeature("llbinary")
+	registerFeature("lllbinary")
+	registerFeature("llalpha")
+	registerFeature("lllalpha")
+	registerFeature("llllvar")
+	registerFeature("llllnumeric")
+}
+
 const (
 	// ASCII is ASCII encoding
 	ASCII = iota
@@ -15,6 +30,16 @@ const (
 	BCD
 	// rBCD is "right-aligned" BCD with odd length (for ex. "643" as [6 67] == "0643"), only for Numeric, Llnumeric and Lllnumeric fields
 	rBCD
+	// BinaryLen2 is a 2 byte big-endian (network byte order) length head, only for Llvar fields
+	BinaryLen2
+	// BinaryLen2LE is a 2 byte little-endian length head, only for Llvar fields
+	BinaryLen2LE
+	// ZoneBCD is zoned decimal, one byte per character with the zone in the
+	// high nibble (0xF for digits, 0xC-0xE for letters), only for Alphanumeric fields
+	ZoneBCD
+	// EBCDIC is EBCDIC (code page 037) encoding of printable ASCII, for values
+	// and length heads
+	EBCDIC
 )
 
 const (
@@ -25,8 +50,79 @@ const (
 	ERR_VALUE_TOO_LONG         string = "length of value is longer than definition; type=%s, def_len=%d, len=%d"
 	ERR_BAD_RAW                string = "bad raw data"
 	ERR_PARSE_LENGTH_FAILED    string = "parse length head failed"
+	ERR_VALUE_NOT_ALLOWED      string = "value is not allowed; type=%s, value=%q"
+)
+
+var (
+	// ErrNegativeAmount is returned by Numeric.Add when the result is negative
+	ErrNegativeAmount = errors.New("amount is negative")
+
+	// ErrOverflow is returned by Numeric.Add when the result does not fit
+	// into the length of the value
+	ErrOverflow = errors.New("amount overflows field length")
 )
 
+// ErrNilField is returned by the methods of a nil field
+type ErrNilField struct {
+	// Type is the name of the field type
+	Type string
+}
+
+func (e *ErrNilField) Error() string {
+	return fmt.Sprintf("nil %s field", e.Type)
+}
+
+// ErrInvalidBCDLength is returned when a BCD length head contains a nibble
+// which is not a decimal digit, or a non-zero pad nibble
+type ErrInvalidBCDLength struct {
+	// Byte is the raw head byte holding the invalid nibble
+	Byte byte
+	// Position is the index of the invalid nibble within the head
+	Position int
+}
+
+func (e *ErrInvalidBCDLength) Error() string {
+	return fmt.Sprintf("%s: invalid BCD byte 0x%02x at nibble %d", ERR_PARSE_LENGTH_FAILED, e.Byte, e.Position)
+}
+
+// ErrOddDigitCount is returned when a BCD numeric field tagged
+// evendigits:"true" holds an odd number of digits, which would be padded
+type ErrOddDigitCount struct {
+	// Field is the field number
+	Field int
+	// Len is the number of digits of the value
+	Len int
+}
+
+func (e *ErrOddDigitCount) Error() string {
+	return fmt.Sprintf("field %d: odd number of digits %d, even required", e.Field, e.Len)
+}
+
+// bcdLength decodes a BCD length head of the given number of digits, left
+// padded with zero nibbles. Nibbles are checked directly, so no
+// intermediate string is built.
+func bcdLength(raw []byte, digits int) (int, error) {
+	pad := len(raw)*2 - digits
+	n := 0
+	for i, b := range raw {
+		hi, lo := int(b>>4), int(b&0x0f)
+		if hi > 9 {
+			return 0, &ErrInvalidBCDLength{b, i * 2}
+		}
+		if lo > 9 {
+			return 0, &ErrInvalidBCDLength{b, i*2 + 1}
+		}
+		if i*2 < pad && hi != 0 {
+			return 0, &ErrInvalidBCDLength{b, i * 2}
+		}
+		if i*2+1 < pad && lo != 0 {
+			return 0, &ErrInvalidBCDLength{b, i*2 + 1}
+		}
+		n = n*100 + hi*10 + lo
+	}
+	return n, nil
+}
+
 // Iso8583Type interface for ISO 8583 fields
 type Iso8583Type interface {
 	// Byte representation of current field.
@@ -38,31 +134,96 @@ type Iso8583Type interface {
 
 	// IsEmpty check is field empty
 	IsEmpty() bool
+
+	// Copy returns a deep copy of the field with the same concrete type
+	Copy() Iso8583Type
 }
 
 // A Numeric contains numeric value only in fix length. It holds numeric
 // value as a string. Supportted encoder are ascii, bcd and rbcd. Length is
 // required for marshalling and unmarshalling.
+//
+// A value set with SetInt or SetUint is held as an integer instead, with
+// Value left empty, and encoded straight from it; Digits returns it as a
+// string. Setting Value to digits, or Load, replaces it.
 type Numeric struct {
 	Value string
+
+	// num is the value set by SetInt or SetUint, used while Value is empty
+	num    uint64
+	hasNum bool
 }
 
 // NewNumeric create new Numeric field
 func NewNumeric(val string) *Numeric {
-	return &Numeric{val}
+	return &Numeric{Value: val}
+}
+
+// NewNumericFixed create new Numeric field holding val zero-padded to
+// length, so Value is the value as sent with the ascii encoder. val must be
+// all digits and not longer than length.
+func NewNumericFixed(val string, length int) (*Numeric, error) {
+	for i := 0; i < len(val); i++ {
+		if val[i] < '0' || val[i] > '9' {
+			return nil, fmt.Errorf("invalid digit %q at %d in Numeric value", val[i], i)
+		}
+	}
+	if len(val) > length {
+		return nil, &ErrValueTooLong{"Numeric", length, len(val)}
+	}
+	return NewNumeric(strings.Repeat("0", length-len(val)) + val), nil
 }
 
 // IsEmpty check Numeric field for empty value
 func (n *Numeric) IsEmpty() bool {
+	if n == nil {
+		return true
+	}
+	if _, ok := n.intValue(); ok {
+		return false
+	}
 	return utf8.RuneCountInString(n.Value) == 0
 }
 
+// intValue returns the value set by SetInt or SetUint, if Value has not
+// been set since
+func (n *Numeric) intValue() (uint64, bool) {
+	return n.num, n.hasNum && n.Value == ""
+}
+
+// Digits returns the value as a string of digits: Value, or the digits of
+// the integer set with SetInt or SetUint
+func (n *Numeric) Digits() string {
+	if n == nil {
+		return ""
+	}
+	if v, ok := n.intValue(); ok {
+		return strconv.FormatUint(v, 10)
+	}
+	return n.Value
+}
+
+// Copy returns a deep copy of the Numeric field
+func (n *Numeric) Copy() Iso8583Type {
+	if n == nil {
+		return (*Numeric)(nil)
+	}
+	c := *n
+	return &c
+}
+
 // Bytes encode Numeric field to bytes
 func (n *Numeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
-	val := []byte(n.Value)
+	if n == nil {
+		return nil, &ErrNilField{"Numeric"}
+	}
 	if length == -1 {
-		return nil, errors.New(ERR_MISSING_LENGTH)
+		return nil, &ErrMissingLength{}
+	}
+	if v, ok := n.intValue(); ok && encoder != EBCDIC {
+		return appendNumericInt(nil, v, encoder, length)
 	}
+	val := []byte(n.Digits())
 	// if encoder == rBCD then length can be, for example, 3,
 	// but value can be, for example, "0631" (after decode from rBCD, because BCD use 1 byte for 2 digits),
 	// and we can encode it only if first digit == 0
@@ -74,7 +235,7 @@ func (n *Numeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	}
 
 	if utf8.RuneCount(val) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Numeric", length, utf8.RuneCount(val)))
+		return nil, &ErrValueTooLong{"Numeric", length, utf8.RuneCount(val)}
 	}
 	if utf8.RuneCount(val) < length {
 		val = append([]byte(strings.Repeat("0", length-utf8.RuneCount(val))), val...)
@@ -86,40 +247,217 @@ func (n *Numeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 		return rbcd(val), nil
 	case ASCII:
 		return val, nil
+	case EBCDIC:
+		return ebcdicEncode(val)
+	default:
+		return nil, &ErrInvalidEncoder{encoder}
+	}
+}
+
+// AppendBytes appends the field encoded as by Bytes to dst and returns the
+// extended slice. A value set with SetInt or SetUint is written straight
+// from the integer with the ascii, bcd and rbcd encoders, so nothing is
+// allocated when dst has room.
+func (n *Numeric) AppendBytes(dst []byte, encoder, length int) ([]byte, error) {
+	if n == nil {
+		return nil, &ErrNilField{"Numeric"}
+	}
+	if v, ok := n.intValue(); ok && encoder != EBCDIC {
+		if length == -1 {
+			return nil, &ErrMissingLength{}
+		}
+		return appendNumericInt(dst, v, encoder, length)
+	}
+	b, err := n.Bytes(encoder, ASCII, length)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, b...), nil
+}
+
+// appendNumericInt appends v zero-padded to length digits to dst, in ascii
+// or packed in BCD nibbles
+func appendNumericInt(dst []byte, v uint64, encoder, length int) ([]byte, error) {
+	digits := 1
+	for x := v; x >= 10; x /= 10 {
+		digits++
+	}
+	if digits > length {
+		return nil, &ErrValueTooLong{"Numeric", length, digits}
+	}
+	switch encoder {
+	case ASCII:
+		dst, out := growBytes(dst, length)
+		for i := length - 1; i >= 0; i-- {
+			out[i] = byte('0' + v%10)
+			v /= 10
+		}
+		return dst, nil
+	case BCD, rBCD:
+		dst, out := growBytes(dst, (length+1)/2)
+		for i := range out {
+			out[i] = 0
+		}
+		// the last digit goes into the last nibble, except for an odd
+		// length in left-aligned BCD, which ends with a pad nibble
+		last := len(out)*2 - 1
+		if encoder == BCD && length%2 != 0 {
+			last--
+		}
+		for pos := last; pos > last-length; pos-- {
+			d := byte(v % 10)
+			v /= 10
+			if pos%2 == 0 {
+				out[pos/2] |= d << 4
+			} else {
+				out[pos/2] |= d
+			}
+		}
+		return dst, nil
 	default:
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, &ErrInvalidEncoder{encoder}
+	}
+}
+
+// growBytes extends b by n bytes and returns it with the added bytes
+func growBytes(b []byte, n int) ([]byte, []byte) {
+	if cap(b)-len(b) < n {
+		grown := make([]byte, len(b), len(b)+n)
+		copy(grown, b)
+		b = grown
 	}
+	b = b[:len(b)+n]
+	return b, b[len(b)-n:]
 }
 
 // Load decode Numeric field from bytes
 func (n *Numeric) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	if n == nil {
+		return 0, &ErrNilField{"Numeric"}
+	}
+	n.hasNum = false
 	if length == -1 {
-		return 0, errors.New(ERR_MISSING_LENGTH)
+		return 0, &ErrMissingLength{}
 	}
 	switch encoder {
 	case BCD:
 		l := (length + 1) / 2
 		if utf8.RuneCount(raw) < l {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, &ErrBadRaw{"Numeric", l, len(raw)}
 		}
 		n.Value = string(bcdl2Ascii(raw[:l], length))
 		return l, nil
 	case rBCD:
 		l := (length + 1) / 2
 		if utf8.RuneCount(raw) < l {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, &ErrBadRaw{"Numeric", l, len(raw)}
 		}
 		n.Value = string(bcdr2Ascii(raw[0:l], length))
 		return l, nil
 	case ASCII:
 		if utf8.RuneCount(raw) < length {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, &ErrBadRaw{"Numeric", length, len(raw)}
 		}
 		n.Value = string(raw[:length])
 		return length, nil
+	case EBCDIC:
+		if len(raw) < length {
+			return 0, &ErrBadRaw{"Numeric", length, len(raw)}
+		}
+		val, err := ebcdicDecode(raw[:length])
+		if err != nil {
+			return 0, err
+		}
+		n.Value = string(val)
+		return length, nil
 	default:
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, &ErrInvalidEncoder{encoder}
+	}
+}
+
+// SetInt sets the value to v, held as an integer and encoded without
+// building a string; Bytes zero-pads it to the field length. Value is left
+// empty. Negative values are not numeric and return an error.
+func (n *Numeric) SetInt(v int64) error {
+	if n == nil {
+		return &ErrNilField{"Numeric"}
+	}
+	if v < 0 {
+		return ErrNegativeAmount
+	}
+	return n.SetUint(uint64(v))
+}
+
+// Int returns the value as an integer. A decoded value is parsed from
+// Value only when Int is called. Values which do not fit into int64 return
+// an error; Value or Digits still hold them.
+func (n *Numeric) Int() (int64, error) {
+	if n == nil {
+		return 0, &ErrNilField{"Numeric"}
+	}
+	if v, ok := n.intValue(); ok {
+		if v > math.MaxInt64 {
+			return 0, &strconv.NumError{Func: "ParseInt", Num: n.Digits(), Err: strconv.ErrRange}
+		}
+		return int64(v), nil
+	}
+	return strconv.ParseInt(n.Value, 10, 64)
+}
+
+// SetUint sets the value to v, as SetInt does, for values of up to 20
+// digits beyond int64
+func (n *Numeric) SetUint(v uint64) error {
+	if n == nil {
+		return &ErrNilField{"Numeric"}
+	}
+	n.Value = ""
+	n.num = v
+	n.hasNum = true
+	return nil
+}
+
+// Uint returns the value as an unsigned integer, as Int does. Values of 20
+// digits which do not fit into uint64 return an error; Value still holds
+// them.
+func (n *Numeric) Uint() (uint64, error) {
+	if n == nil {
+		return 0, &ErrNilField{"Numeric"}
+	}
+	if v, ok := n.intValue(); ok {
+		return v, nil
+	}
+	return strconv.ParseUint(n.Value, 10, 64)
+}
+
+// Add returns a new Numeric holding the value plus delta, zero-padded to the
+// length of the value, or held as an integer like the value. The Numeric
+// itself is not changed.
+func (n *Numeric) Add(delta int64) (*Numeric, error) {
+	if n == nil {
+		return nil, &ErrNilField{"Numeric"}
+	}
+	digits := n.Digits()
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if (delta > 0 && v > math.MaxInt64-delta) || (delta < 0 && v < math.MinInt64-delta) {
+		return nil, ErrOverflow
+	}
+	v += delta
+	if v < 0 {
+		return nil, ErrNegativeAmount
 	}
+	if _, ok := n.intValue(); ok {
+		// an integer has no width to keep
+		ret := &Numeric{}
+		return ret, ret.SetInt(v)
+	}
+	res := fmt.Sprintf("%0*d", len(digits), v)
+	if len(res) > len(digits) {
+		return nil, ErrOverflow
+	}
+	return NewNumeric(res), nil
 }
 
 // An Alphanumeric contains alphanumeric value in fix length. The only
@@ -127,6 +465,13 @@ func (n *Numeric) Load(raw []byte, encoder, lenEncoder, length int) (int, error)
 // unmarshalling.
 type Alphanumeric struct {
 	Value string
+
+	// NullPad strips trailing null bytes ("\x00") from the value on Load,
+	// for hosts that pad alphanumeric fields with nulls instead of spaces.
+	NullPad bool
+
+	// PadChar, when not zero, is stripped from the right of the value on Load.
+	PadChar byte
 }
 
 // NewAlphanumeric create new Alphanumeric field
@@ -134,38 +479,183 @@ func NewAlphanumeric(val string) *Alphanumeric {
 	return &Alphanumeric{Value: val}
 }
 
+// NewAlphanumericNullPad create new Alphanumeric field which strips null
+// padding on Load
+func NewAlphanumericNullPad(val string) *Alphanumeric {
+	return &Alphanumeric{Value: val, NullPad: true}
+}
+
 // IsEmpty check Alphanumeric field for empty value
 func (a *Alphanumeric) IsEmpty() bool {
-	return utf8.RuneCountInString(a.Value) == 0
+	return a == nil || utf8.RuneCountInString(a.Value) == 0
+}
+
+// Copy returns a deep copy of the Alphanumeric field
+func (a *Alphanumeric) Copy() Iso8583Type {
+	if a == nil {
+		return (*Alphanumeric)(nil)
+	}
+	c := *a
+	return &c
 }
 
 // Bytes encode Alphanumeric field to bytes
 func (a *Alphanumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if a == nil {
+		return nil, &ErrNilField{"Alphanumeric"}
+	}
 	val := []byte(a.Value)
 	if length == -1 {
-		return nil, errors.New(ERR_MISSING_LENGTH)
+		return nil, &ErrMissingLength{}
 	}
 	if utf8.RuneCount(val) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Alphanumeric", length, utf8.RuneCount(val)))
+		return nil, &ErrValueTooLong{"Alphanumeric", length, utf8.RuneCount(val)}
 	}
 	if utf8.RuneCount(val) < length {
 		val = append([]byte(strings.Repeat(" ", length-utf8.RuneCount(val))), val...)
 	}
+	if encoder == ZoneBCD {
+		return zoneEncode(val)
+	}
+	if encoder == EBCDIC {
+		return ebcdicEncode(val)
+	}
 	return val, nil
 }
 
 // Load decode Alphanumeric field from bytes
 func (a *Alphanumeric) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	if a == nil {
+		return 0, &ErrNilField{"Alphanumeric"}
+	}
 	if length == -1 {
-		return 0, errors.New(ERR_MISSING_LENGTH)
+		return 0, &ErrMissingLength{}
 	}
 	if utf8.RuneCount(raw) < length {
-		return 0, errors.New(ERR_BAD_RAW)
+		return 0, &ErrBadRaw{"Alphanumeric", length, len(raw)}
+	}
+	val := raw[:length]
+	if encoder == ZoneBCD || encoder == EBCDIC {
+		decode := zoneDecode
+		if encoder == EBCDIC {
+			decode = ebcdicDecode
+		}
+		var err error
+		if val, err = decode(val); err != nil {
+			return 0, err
+		}
 	}
-	a.Value = string(raw[:length])
+	if a.NullPad {
+		val = trimRightByte(val, 0x00)
+	}
+	if a.PadChar != 0 {
+		val = trimRightByte(val, a.PadChar)
+	}
+	a.Value = string(val)
 	return length, nil
 }
 
+// An Enum is an Alphanumeric restricted to a list of allowed values, such as
+// the response codes of field 39. The value is checked on Bytes and Load.
+// The values are given to NewEnum, or by an allowed tag of the template,
+// such as allowed:"00,05,51", for Enums created by Parser. An Enum without
+// either accepts any value.
+type Enum struct {
+	Alphanumeric
+	allowed map[string]struct{}
+}
+
+// NewEnum create new Enum field. It returns an error if val is not one of
+// allowedValues.
+func NewEnum(val string, allowedValues []string) (*Enum, error) {
+	e := &Enum{Alphanumeric: Alphanumeric{Value: val}}
+	e.setAllowed(allowedValues)
+	if err := e.check(val); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// IsEmpty check Enum field for empty value
+func (e *Enum) IsEmpty() bool {
+	return e == nil || e.Alphanumeric.IsEmpty()
+}
+
+// Copy returns a deep copy of the Enum field
+func (e *Enum) Copy() Iso8583Type {
+	if e == nil {
+		return (*Enum)(nil)
+	}
+	c := *e
+	return &c
+}
+
+func (e *Enum) setAllowed(values []string) {
+	e.allowed = make(map[string]struct{}, len(values))
+	for _, v := range values {
+		e.allowed[v] = struct{}{}
+	}
+}
+
+// withAllowed returns e, or a copy restricted to values when e has no
+// allowed values of its own
+func (e *Enum) withAllowed(values []string) *Enum {
+	if e.allowed != nil {
+		return e
+	}
+	c := *e
+	c.setAllowed(values)
+	return &c
+}
+
+func (e *Enum) check(val string) error {
+	if e.allowed == nil {
+		return nil
+	}
+	if _, ok := e.allowed[val]; !ok {
+		return &ErrValueNotAllowed{"Enum", val}
+	}
+	return nil
+}
+
+// Bytes encode Enum field to bytes
+func (e *Enum) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if e == nil {
+		return nil, &ErrNilField{"Enum"}
+	}
+	if err := e.check(e.Value); err != nil {
+		return nil, err
+	}
+	return e.Alphanumeric.Bytes(encoder, lenEncoder, length)
+}
+
+// Load decode Enum field from bytes
+func (e *Enum) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	if e == nil {
+		return 0, &ErrNilField{"Enum"}
+	}
+	a := e.Alphanumeric
+	read, err := a.Load(raw, encoder, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.check(a.Value); err != nil {
+		return 0, err
+	}
+	e.Alphanumeric = a
+	return read, nil
+}
+
+// trimRightByte cuts all trailing c bytes. Unlike strings.TrimRight it
+// compares raw bytes, so non-ASCII pad bytes such as 0xFF are handled.
+func trimRightByte(b []byte, c byte) []byte {
+	i := len(b)
+	for i > 0 && b[i-1] == c {
+		i--
+	}
+	return b[:i]
+}
+
 // Binary contains binary value
 type Binary struct {
 	Value  []byte
@@ -179,40 +669,91 @@ func NewBinary(d []byte) *Binary {
 
 // IsEmpty check Binary field for empty value
 func (b *Binary) IsEmpty() bool {
-	return utf8.RuneCount(b.Value) == 0
+	return b == nil || utf8.RuneCount(b.Value) == 0
+}
+
+// Copy returns a deep copy of the Binary field
+func (b *Binary) Copy() Iso8583Type {
+	if b == nil {
+		return (*Binary)(nil)
+	}
+	return b.Clone()
 }
 
 // Bytes encode Binary field to bytes
 func (b *Binary) Bytes(encoder, lenEncoder, l int) ([]byte, error) {
+	if b == nil {
+		return nil, &ErrNilField{"Binary"}
+	}
 	length := l
 	if b.FixLen != -1 {
 		length = b.FixLen
 	}
 	if length == -1 {
-		return nil, errors.New(ERR_MISSING_LENGTH)
+		return nil, &ErrMissingLength{}
 	}
 	if utf8.RuneCount(b.Value) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Binary", length, utf8.RuneCount(b.Value)))
+		return nil, &ErrValueTooLong{"Binary", length, utf8.RuneCount(b.Value)}
 	}
 	if utf8.RuneCount(b.Value) < length {
-		return append(b.Value, make([]byte, length-utf8.RuneCount(b.Value))...), nil
+		// pad a copy: appending to b.Value could write into its backing array
+		val := make([]byte, length)
+		copy(val, b.Value)
+		return val, nil
 	}
 	return b.Value, nil
 }
 
 // Load decode Binary field from bytes
 func (b *Binary) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	if b == nil {
+		return 0, &ErrNilField{"Binary"}
+	}
 	if length == -1 {
-		return 0, errors.New(ERR_MISSING_LENGTH)
+		return 0, &ErrMissingLength{}
 	}
 	if utf8.RuneCount(raw) < length {
-		return 0, errors.New(ERR_BAD_RAW)
+		return 0, &ErrBadRaw{"Binary", length, len(raw)}
 	}
 	b.Value = raw[:length]
 	b.FixLen = length
 	return length, nil
 }
 
+// Equal reports whether the value of b equals other
+func (b *Binary) Equal(other []byte) bool {
+	if b == nil {
+		return len(other) == 0
+	}
+	return bytes.Equal(b.Value, other)
+}
+
+// EqualConstantTime reports whether the value of b equals other in time
+// independent of the contents, for comparing MAC and PIN material. Only the
+// lengths may leak through timing.
+func (b *Binary) EqualConstantTime(other []byte) bool {
+	if b == nil {
+		return len(other) == 0
+	}
+	return subtle.ConstantTimeCompare(b.Value, other) == 1
+}
+
+// Clone returns a copy of b which does not share the value bytes
+func (b *Binary) Clone() *Binary {
+	if b == nil {
+		return nil
+	}
+	return &Binary{copyBytes(b.Value), b.FixLen}
+}
+
+// String returns the value of b in uppercase hex
+func (b *Binary) String() string {
+	if b == nil {
+		return ""
+	}
+	return strings.ToUpper(hex.EncodeToString(b.Value))
+}
+
 // Llvar contains bytes in non-fixed length field, first 2 symbols of field contains length
 type Llvar struct {
 	Value []byte
@@ -225,16 +766,35 @@ func NewLlvar(val []byte) *Llvar {
 
 // IsEmpty check Llvar field for empty value
 func (l *Llvar) IsEmpty() bool {
-	return utf8.RuneCount(l.Value) == 0
+	return l == nil || utf8.RuneCount(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Llvar field
+func (l *Llvar) Copy() Iso8583Type {
+	if l == nil {
+		return (*Llvar)(nil)
+	}
+	return &Llvar{copyBytes(l.Value)}
 }
 
 // Bytes encode Llvar field to bytes
 func (l *Llvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Llvar"}
+	}
 	if length != -1 && utf8.RuneCount(l.Value) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Llvar", length, utf8.RuneCount(l.Value)))
+		return nil, &ErrValueTooLong{"Llvar", length, utf8.RuneCount(l.Value)}
 	}
-	if encoder != ASCII {
-		return nil, errors.New(ERR_INVALID_ENCODER)
+	value := l.Value
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		var err error
+		if value, err = ebcdicEncode(l.Value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ErrInvalidEncoder{encoder}
 	}
 
 	lenStr := fmt.Sprintf("%02d", utf8.RuneCount(l.Value))
@@ -244,23 +804,41 @@ func (l *Llvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	case ASCII:
 		lenVal = contentLen
 		if utf8.RuneCount(lenVal) > 2 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
+			return nil, &ErrInvalidLengthHead{"Llvar", 99, utf8.RuneCount(l.Value)}
+		}
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+		if len(lenVal) > 2 {
+			return nil, &ErrInvalidLengthHead{"Llvar", 99, utf8.RuneCount(l.Value)}
 		}
 	case rBCD:
 		fallthrough
 	case BCD:
 		lenVal = rbcd(contentLen)
 		if utf8.RuneCount(lenVal) > 1 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
+			return nil, &ErrInvalidLengthHead{"Llvar", 99, utf8.RuneCount(l.Value)}
+		}
+	case BinaryLen2, BinaryLen2LE:
+		if len(l.Value) > 0xFFFF {
+			return nil, &ErrInvalidLengthHead{"Llvar", 0xFFFF, len(l.Value)}
+		}
+		lenVal = make([]byte, 2)
+		if lenEncoder == BinaryLen2 {
+			binary.BigEndian.PutUint16(lenVal, uint16(len(l.Value)))
+		} else {
+			binary.LittleEndian.PutUint16(lenVal, uint16(len(l.Value)))
 		}
 	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
 	}
-	return append(lenVal, l.Value...), nil
+	return append(lenVal, value...), nil
 }
 
 // Load decode Llvar field from bytes
 func (l *Llvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Llvar"}
+	}
 	// parse length head:
 	var contentLen int
 	switch lenEncoder {
@@ -268,157 +846,523 @@ func (l *Llvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err
 		read = 2
 		contentLen, err = strconv.Atoi(string(raw[:read]))
 		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
+			return 0, &ErrParseLength{string(raw[:2])}
+		}
+	case EBCDIC:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llvar", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
 		}
 	case rBCD:
 		fallthrough
 	case BCD:
 		read = 1
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 2)))
+		contentLen, err = bcdLength(raw[:read], 2)
 		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[0]))
+			return 0, err
+		}
+	case BinaryLen2, BinaryLen2LE:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llvar", read, len(raw)}
+		}
+		if lenEncoder == BinaryLen2 {
+			contentLen = int(binary.BigEndian.Uint16(raw))
+		} else {
+			contentLen = int(binary.LittleEndian.Uint16(raw))
 		}
 	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
 	}
 	if utf8.RuneCount(raw) < (read + contentLen) {
-		return 0, errors.New(ERR_BAD_RAW)
+		return 0, &ErrBadRaw{"Llvar", read + contentLen, len(raw)}
 	}
 	// parse body:
 	l.Value = raw[read : read+contentLen]
 	read += contentLen
-	if encoder != ASCII {
-		return 0, errors.New(ERR_INVALID_ENCODER)
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		if l.Value, err = ebcdicDecode(l.Value); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidEncoder{encoder}
 	}
 
 	return read, nil
 }
 
-// A Llnumeric contains numeric value only in non-fix length, contains length in first 2 symbols. It holds numeric
-// value as a string. Supportted encoder are ascii, bcd and rbcd. Length is
-// required for marshalling and unmarshalling.
-type Llnumeric struct {
+// IsASCIIPrintable checks that all bytes of the value are printable ASCII
+// (0x20-0x7E). It is true for an empty value.
+func (l *Llvar) IsASCIIPrintable() bool {
+	if l == nil {
+		return true
+	}
+	for _, b := range l.Value {
+		if b < 0x20 || b > 0x7E {
+			return false
+		}
+	}
+	return true
+}
+
+// IsNumeric checks that all bytes of the value are ASCII digits. It is true
+// for an empty value.
+func (l *Llvar) IsNumeric() bool {
+	if l == nil {
+		return true
+	}
+	for _, b := range l.Value {
+		if b < '0' || b > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsHex checks that all bytes of the value are ASCII hex digits, in either
+// case. It is true for an empty value.
+func (l *Llvar) IsHex() bool {
+	if l == nil {
+		return true
+	}
+	for _, b := range l.Value {
+		if !(b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// Llalpha contains alphanumeric text in non-fixed length field, first 2
+// symbols of field contains length. Supported encoders are ascii and ebcdic;
+// trailing spaces are trimmed by Load.
+type Llalpha struct {
 	Value string
 }
 
-// NewLlnumeric create new Llnumeric field
-func NewLlnumeric(val string) *Llnumeric {
-	return &Llnumeric{val}
+// NewLlalpha create new Llalpha field
+func NewLlalpha(val string) *Llalpha {
+	return &Llalpha{val}
 }
 
-// IsEmpty check Llnumeric field for empty value
-func (l *Llnumeric) IsEmpty() bool {
-	return utf8.RuneCountInString(l.Value) == 0
+// IsEmpty check Llalpha field for empty value
+func (l *Llalpha) IsEmpty() bool {
+	return l == nil || len(l.Value) == 0
 }
 
-// Bytes encode Llnumeric field to bytes
-func (l *Llnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
-	raw := []byte(l.Value)
-	if length != -1 && utf8.RuneCount(raw) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Llnumeric", length, utf8.RuneCount(raw)))
+// Copy returns a deep copy of the Llalpha field
+func (l *Llalpha) Copy() Iso8583Type {
+	if l == nil {
+		return (*Llalpha)(nil)
 	}
+	c := *l
+	return &c
+}
 
-	val := raw
+// Bytes encode Llalpha field to bytes
+func (l *Llalpha) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Llalpha"}
+	}
+	val := []byte(l.Value)
+	if length != -1 && len(val) > length {
+		return nil, &ErrValueTooLong{"Llalpha", length, len(val)}
+	}
+	if len(val) > 99 {
+		return nil, &ErrInvalidLengthHead{"Llalpha", 99, len(val)}
+	}
 	switch encoder {
 	case ASCII:
-	case BCD:
-		val = lbcd(raw)
-	case rBCD:
-		val = rbcd(raw)
+	case EBCDIC:
+		var err error
+		if val, err = ebcdicEncode(val); err != nil {
+			return nil, err
+		}
 	default:
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, &ErrInvalidEncoder{encoder}
 	}
 
-	lenStr := fmt.Sprintf("%02d", utf8.RuneCount(raw)) // length of digital characters
-	contentLen := []byte(lenStr)
+	contentLen := []byte(fmt.Sprintf("%02d", len(val)))
 	var lenVal []byte
 	switch lenEncoder {
 	case ASCII:
 		lenVal = contentLen
-		if utf8.RuneCount(lenVal) > 2 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
 	case rBCD:
 		fallthrough
 	case BCD:
 		lenVal = rbcd(contentLen)
-		if utf8.RuneCount(lenVal) > 1 || utf8.RuneCount(contentLen) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
 	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
 	}
 	return append(lenVal, val...), nil
 }
 
-// Load decode Llnumeric field from bytes
-func (l *Llnumeric) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+// Load decode Llalpha field from bytes
+func (l *Llalpha) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Llalpha"}
+	}
 	// parse length head:
 	var contentLen int
 	switch lenEncoder {
 	case ASCII:
 		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llalpha", read, len(raw)}
+		}
 		contentLen, err = strconv.Atoi(string(raw[:read]))
 		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
+			return 0, &ErrParseLength{string(raw[:read])}
+		}
+	case EBCDIC:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llalpha", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
 		}
 	case rBCD:
 		fallthrough
 	case BCD:
 		read = 1
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 2)))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[0]))
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llalpha", read, len(raw)}
+		}
+		if contentLen, err = bcdLength(raw[:read], 2); err != nil {
+			return 0, err
 		}
 	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	if contentLen < 0 || len(raw) < read+contentLen {
+		return 0, &ErrBadRaw{"Llalpha", read + contentLen, len(raw)}
 	}
 
 	// parse body:
+	val := raw[read : read+contentLen]
 	switch encoder {
 	case ASCII:
-		if utf8.RuneCount(raw) < (read + contentLen) {
-			return 0, errors.New(ERR_BAD_RAW)
-		}
-		l.Value = string(raw[read : read+contentLen])
-		read += contentLen
-	case rBCD:
-		fallthrough
-	case BCD:
-		bcdLen := (contentLen + 1) / 2
-		if utf8.RuneCount(raw) < (read + bcdLen) {
-			return 0, errors.New(ERR_BAD_RAW)
+	case EBCDIC:
+		if val, err = ebcdicDecode(val); err != nil {
+			return 0, err
 		}
-		l.Value = string(bcdl2Ascii(raw[read:read+bcdLen], contentLen))
-		read += bcdLen
 	default:
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, &ErrInvalidEncoder{encoder}
 	}
-	return read, nil
+	l.Value = string(trimRightByte(val, ' '))
+	return read + contentLen, nil
 }
 
-// Lllvar contains bytes in non-fixed length field, first 3 symbols of field contains length
-type Lllvar struct {
+// Llbinary contains raw bytes in non-fixed length field of up to 99 bytes,
+// such as EMV data. The first 2 symbols (1 byte in bcd or rbcd) of the field
+// contain the number of bytes. The value is written as it is, whatever the
+// encoder.
+type Llbinary struct {
 	Value []byte
 }
 
-// NewLllvar create new Lllvar field
-func NewLllvar(val []byte) *Lllvar {
-	return &Lllvar{val}
+// NewLlbinary create new Llbinary field
+func NewLlbinary(val []byte) *Llbinary {
+	return &Llbinary{Value: val}
 }
 
-// IsEmpty check Lllvar field for empty value
-func (l *Lllvar) IsEmpty() bool {
-	return utf8.RuneCount(l.Value) == 0
+// IsEmpty check Llbinary field for empty value
+func (l *Llbinary) IsEmpty() bool {
+	return l == nil || len(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Llbinary field
+func (l *Llbinary) Copy() Iso8583Type {
+	if l == nil {
+		return (*Llbinary)(nil)
+	}
+	return &Llbinary{Value: copyBytes(l.Value)}
+}
+
+// String returns the value in uppercase hex
+func (l *Llbinary) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.ToUpper(hex.EncodeToString(l.Value))
+}
+
+// Bytes encode Llbinary field to bytes
+func (l *Llbinary) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Llbinary"}
+	}
+	if length != -1 && len(l.Value) > length {
+		return nil, &ErrValueTooLong{"Llbinary", length, len(l.Value)}
+	}
+	if len(l.Value) > 99 {
+		return nil, &ErrInvalidLengthHead{"Llbinary", 99, len(l.Value)}
+	}
+
+	contentLen := []byte(fmt.Sprintf("%02d", len(l.Value)))
+	var lenVal []byte
+	switch lenEncoder {
+	case ASCII:
+		lenVal = contentLen
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+	case rBCD:
+		fallthrough
+	case BCD:
+		lenVal = rbcd(contentLen)
+	default:
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	return append(lenVal, l.Value...), nil
+}
+
+// Load decode Llbinary field from bytes
+func (l *Llbinary) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Llbinary"}
+	}
+	// parse length head:
+	var contentLen int
+	switch lenEncoder {
+	case ASCII:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llbinary", read, len(raw)}
+		}
+		contentLen, err = strconv.Atoi(string(raw[:read]))
+		if err != nil {
+			return 0, &ErrParseLength{string(raw[:read])}
+		}
+	case EBCDIC:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llbinary", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
+		}
+	case rBCD:
+		fallthrough
+	case BCD:
+		read = 1
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llbinary", read, len(raw)}
+		}
+		if contentLen, err = bcdLength(raw[:read], 2); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	if contentLen < 0 || len(raw) < read+contentLen {
+		return 0, &ErrBadRaw{"Llbinary", read + contentLen, len(raw)}
+	}
+	l.Value = raw[read : read+contentLen]
+	return read + contentLen, nil
+}
+
+// A Llnumeric contains numeric value only in non-fix length, contains length in first 2 symbols. It holds numeric
+// value as a string. Supportted encoder are ascii, bcd and rbcd. Length is
+// required for marshalling and unmarshalling.
+type Llnumeric struct {
+	Value string
+}
+
+// NewLlnumeric create new Llnumeric field
+func NewLlnumeric(val string) *Llnumeric {
+	return &Llnumeric{val}
+}
+
+// IsEmpty check Llnumeric field for empty value
+func (l *Llnumeric) IsEmpty() bool {
+	return l == nil || utf8.RuneCountInString(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Llnumeric field
+func (l *Llnumeric) Copy() Iso8583Type {
+	if l == nil {
+		return (*Llnumeric)(nil)
+	}
+	c := *l
+	return &c
+}
+
+// Bytes encode Llnumeric field to bytes
+func (l *Llnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Llnumeric"}
+	}
+	raw := []byte(l.Value)
+	if length != -1 && utf8.RuneCount(raw) > length {
+		return nil, &ErrValueTooLong{"Llnumeric", length, utf8.RuneCount(raw)}
+	}
+
+	val := raw
+	switch encoder {
+	case ASCII:
+	case BCD:
+		val = lbcd(raw)
+	case rBCD:
+		val = rbcd(raw)
+	case EBCDIC:
+		var err error
+		if val, err = ebcdicEncode(raw); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ErrInvalidEncoder{encoder}
+	}
+
+	lenStr := fmt.Sprintf("%02d", utf8.RuneCount(raw)) // length of digital characters
+	contentLen := []byte(lenStr)
+	var lenVal []byte
+	switch lenEncoder {
+	case ASCII:
+		lenVal = contentLen
+		if utf8.RuneCount(lenVal) > 2 {
+			return nil, &ErrInvalidLengthHead{"Llnumeric", 99, utf8.RuneCount(raw)}
+		}
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+		if len(lenVal) > 2 {
+			return nil, &ErrInvalidLengthHead{"Llnumeric", 99, utf8.RuneCount(raw)}
+		}
+	case rBCD:
+		fallthrough
+	case BCD:
+		lenVal = rbcd(contentLen)
+		if utf8.RuneCount(lenVal) > 1 || utf8.RuneCount(contentLen) > 3 {
+			return nil, &ErrInvalidLengthHead{"Llnumeric", 99, utf8.RuneCount(raw)}
+		}
+	default:
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	return append(lenVal, val...), nil
+}
+
+// Load decode Llnumeric field from bytes
+func (l *Llnumeric) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Llnumeric"}
+	}
+	// parse length head:
+	var contentLen int
+	switch lenEncoder {
+	case ASCII:
+		read = 2
+		contentLen, err = strconv.Atoi(string(raw[:read]))
+		if err != nil {
+			return 0, &ErrParseLength{string(raw[:2])}
+		}
+	case EBCDIC:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llnumeric", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
+		}
+	case rBCD:
+		fallthrough
+	case BCD:
+		read = 1
+		contentLen, err = bcdLength(raw[:read], 2)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+
+	// parse body:
+	switch encoder {
+	case ASCII:
+		if utf8.RuneCount(raw) < (read + contentLen) {
+			return 0, &ErrBadRaw{"Llnumeric", read + contentLen, len(raw)}
+		}
+		l.Value = string(raw[read : read+contentLen])
+		read += contentLen
+	case EBCDIC:
+		if len(raw) < read+contentLen {
+			return 0, &ErrBadRaw{"Llnumeric", read + contentLen, len(raw)}
+		}
+		val, err := ebcdicDecode(raw[read : read+contentLen])
+		if err != nil {
+			return 0, err
+		}
+		l.Value = string(val)
+		read += contentLen
+	case rBCD:
+		fallthrough
+	case BCD:
+		bcdLen := (contentLen + 1) / 2
+		if utf8.RuneCount(raw) < (read + bcdLen) {
+			return 0, &ErrBadRaw{"Llnumeric", read + bcdLen, len(raw)}
+		}
+		l.Value = string(bcdl2Ascii(raw[read:read+bcdLen], contentLen))
+		read += bcdLen
+	default:
+		return 0, &ErrInvalidEncoder{encoder}
+	}
+	return read, nil
+}
+
+// Lllvar contains bytes in non-fixed length field, first 3 symbols of field contains length
+type Lllvar struct {
+	Value []byte
+
+	// Encoding is the encoding of the content, used by String, StringValue
+	// and MarshalJSON. It does not change the wire format. A raw field
+	// takes the encoding of its content tag when it is loaded.
+	Encoding ContentEncoding
+}
+
+// NewLllvar create new Lllvar field
+func NewLllvar(val []byte) *Lllvar {
+	return &Lllvar{Value: val}
+}
+
+// IsEmpty check Lllvar field for empty value
+func (l *Lllvar) IsEmpty() bool {
+	return l == nil || utf8.RuneCount(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Lllvar field
+func (l *Lllvar) Copy() Iso8583Type {
+	if l == nil {
+		return (*Lllvar)(nil)
+	}
+	c := *l
+	c.Value = copyBytes(l.Value)
+	return &c
 }
 
 // Bytes encode Lllvar field to bytes
 func (l *Lllvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Lllvar"}
+	}
 	if length != -1 && utf8.RuneCount(l.Value) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Lllvar", length, utf8.RuneCount(l.Value)))
+		return nil, &ErrValueTooLong{"Lllvar", length, utf8.RuneCount(l.Value)}
 	}
-	if encoder != ASCII {
-		return nil, errors.New(ERR_INVALID_ENCODER)
+	value := l.Value
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		var err error
+		if value, err = ebcdicEncode(l.Value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ErrInvalidEncoder{encoder}
 	}
 
 	lenStr := fmt.Sprintf("%03d", utf8.RuneCount(l.Value))
@@ -428,23 +1372,31 @@ func (l *Lllvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	case ASCII:
 		lenVal = contentLen
 		if utf8.RuneCount(lenVal) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
+			return nil, &ErrInvalidLengthHead{"Lllvar", 999, utf8.RuneCount(l.Value)}
+		}
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+		if len(lenVal) > 3 {
+			return nil, &ErrInvalidLengthHead{"Lllvar", 999, utf8.RuneCount(l.Value)}
 		}
 	case rBCD:
 		fallthrough
 	case BCD:
 		lenVal = rbcd(contentLen)
 		if utf8.RuneCount(lenVal) > 2 || utf8.RuneCount(contentLen) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
+			return nil, &ErrInvalidLengthHead{"Lllvar", 999, utf8.RuneCount(l.Value)}
 		}
 	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
 	}
-	return append(lenVal, l.Value...), nil
+	return append(lenVal, value...), nil
 }
 
 // Load decode Lllvar field from bytes
 func (l *Lllvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Lllvar"}
+	}
 	// parse length head:
 	var contentLen int
 	switch lenEncoder {
@@ -452,32 +1404,295 @@ func (l *Lllvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, er
 		read = 3
 		contentLen, err = strconv.Atoi(string(raw[:read]))
 		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:3]))
+			return 0, &ErrParseLength{string(raw[:3])}
+		}
+	case EBCDIC:
+		read = 3
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllvar", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
 		}
 	case rBCD:
 		fallthrough
 	case BCD:
 		read = 2
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 3)))
+		contentLen, err = bcdLength(raw[:read], 3)
 		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
+			return 0, err
 		}
 	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
 	}
-	if utf8.RuneCount(raw) < (read + contentLen) {
-		return 0, errors.New(ERR_BAD_RAW)
+	if contentLen > 999 {
+		return 0, &ErrValueTooLong{"Lllvar", 999, contentLen}
+	}
+	if length != -1 && contentLen > length {
+		return 0, &ErrValueTooLong{"Lllvar", length, contentLen}
+	}
+	if contentLen < 0 || utf8.RuneCount(raw) < (read+contentLen) {
+		return 0, &ErrBadRaw{"Lllvar", read + contentLen, len(raw)}
 	}
 	// parse body:
 	l.Value = raw[read : read+contentLen]
 	read += contentLen
-	if encoder != ASCII {
-		return 0, errors.New(ERR_INVALID_ENCODER)
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		if l.Value, err = ebcdicDecode(l.Value); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidEncoder{encoder}
 	}
 
 	return read, nil
 }
 
+// Lllalpha contains alphanumeric text in non-fixed length field, first 3
+// symbols of field contains length. Supported encoders are ascii and ebcdic;
+// trailing spaces are trimmed by Load.
+type Lllalpha struct {
+	Value string
+}
+
+// NewLllalpha create new Lllalpha field
+func NewLllalpha(val string) *Lllalpha {
+	return &Lllalpha{val}
+}
+
+// IsEmpty check Lllalpha field for empty value
+func (l *Lllalpha) IsEmpty() bool {
+	return l == nil || len(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Lllalpha field
+func (l *Lllalpha) Copy() Iso8583Type {
+	if l == nil {
+		return (*Lllalpha)(nil)
+	}
+	c := *l
+	return &c
+}
+
+// Bytes encode Lllalpha field to bytes
+func (l *Lllalpha) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Lllalpha"}
+	}
+	val := []byte(l.Value)
+	if length != -1 && len(val) > length {
+		return nil, &ErrValueTooLong{"Lllalpha", length, len(val)}
+	}
+	if len(val) > 999 {
+		return nil, &ErrInvalidLengthHead{"Lllalpha", 999, len(val)}
+	}
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		var err error
+		if val, err = ebcdicEncode(val); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ErrInvalidEncoder{encoder}
+	}
+
+	contentLen := []byte(fmt.Sprintf("%03d", len(val)))
+	var lenVal []byte
+	switch lenEncoder {
+	case ASCII:
+		lenVal = contentLen
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+	case rBCD:
+		fallthrough
+	case BCD:
+		lenVal = rbcd(contentLen)
+	default:
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	return append(lenVal, val...), nil
+}
+
+// Load decode Lllalpha field from bytes
+func (l *Lllalpha) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Lllalpha"}
+	}
+	// parse length head:
+	var contentLen int
+	switch lenEncoder {
+	case ASCII:
+		read = 3
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllalpha", read, len(raw)}
+		}
+		contentLen, err = strconv.Atoi(string(raw[:read]))
+		if err != nil {
+			return 0, &ErrParseLength{string(raw[:read])}
+		}
+	case EBCDIC:
+		read = 3
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllalpha", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
+		}
+	case rBCD:
+		fallthrough
+	case BCD:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllalpha", read, len(raw)}
+		}
+		if contentLen, err = bcdLength(raw[:read], 3); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	if contentLen > 999 {
+		return 0, &ErrValueTooLong{"Lllalpha", 999, contentLen}
+	}
+	if length != -1 && contentLen > length {
+		return 0, &ErrValueTooLong{"Lllalpha", length, contentLen}
+	}
+	if contentLen < 0 || len(raw) < read+contentLen {
+		return 0, &ErrBadRaw{"Lllalpha", read + contentLen, len(raw)}
+	}
+
+	// parse body:
+	val := raw[read : read+contentLen]
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		if val, err = ebcdicDecode(val); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidEncoder{encoder}
+	}
+	l.Value = string(trimRightByte(val, ' '))
+	return read + contentLen, nil
+}
+
+// Lllbinary contains raw bytes in non-fixed length field of up to 999 bytes,
+// such as private use data. The first 3 symbols (2 bytes in bcd or rbcd) of
+// the field contain the number of bytes. The value is written as it is, whatever the
+// encoder.
+type Lllbinary struct {
+	Value []byte
+}
+
+// NewLllbinary create new Lllbinary field
+func NewLllbinary(val []byte) *Lllbinary {
+	return &Lllbinary{Value: val}
+}
+
+// IsEmpty check Lllbinary field for empty value
+func (l *Lllbinary) IsEmpty() bool {
+	return l == nil || len(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Lllbinary field
+func (l *Lllbinary) Copy() Iso8583Type {
+	if l == nil {
+		return (*Lllbinary)(nil)
+	}
+	return &Lllbinary{Value: copyBytes(l.Value)}
+}
+
+// String returns the value in uppercase hex
+func (l *Lllbinary) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.ToUpper(hex.EncodeToString(l.Value))
+}
+
+// Bytes encode Lllbinary field to bytes
+func (l *Lllbinary) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Lllbinary"}
+	}
+	if length != -1 && len(l.Value) > length {
+		return nil, &ErrValueTooLong{"Lllbinary", length, len(l.Value)}
+	}
+	if len(l.Value) > 999 {
+		return nil, &ErrInvalidLengthHead{"Lllbinary", 999, len(l.Value)}
+	}
+
+	contentLen := []byte(fmt.Sprintf("%03d", len(l.Value)))
+	var lenVal []byte
+	switch lenEncoder {
+	case ASCII:
+		lenVal = contentLen
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+	case rBCD:
+		fallthrough
+	case BCD:
+		lenVal = rbcd(contentLen)
+	default:
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	return append(lenVal, l.Value...), nil
+}
+
+// Load decode Lllbinary field from bytes
+func (l *Lllbinary) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Lllbinary"}
+	}
+	// parse length head:
+	var contentLen int
+	switch lenEncoder {
+	case ASCII:
+		read = 3
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllbinary", read, len(raw)}
+		}
+		contentLen, err = strconv.Atoi(string(raw[:read]))
+		if err != nil {
+			return 0, &ErrParseLength{string(raw[:read])}
+		}
+	case EBCDIC:
+		read = 3
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllbinary", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
+		}
+	case rBCD:
+		fallthrough
+	case BCD:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllbinary", read, len(raw)}
+		}
+		if contentLen, err = bcdLength(raw[:read], 3); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	if contentLen > 999 {
+		return 0, &ErrValueTooLong{"Lllbinary", 999, contentLen}
+	}
+	if length != -1 && contentLen > length {
+		return 0, &ErrValueTooLong{"Lllbinary", length, contentLen}
+	}
+	if contentLen < 0 || len(raw) < read+contentLen {
+		return 0, &ErrBadRaw{"Lllbinary", read + contentLen, len(raw)}
+	}
+	l.Value = raw[read : read+contentLen]
+	return read + contentLen, nil
+}
+
 // A Lllnumeric contains numeric value only in non-fix length, contains length in first 3 symbols. It holds numeric
 // value as a string. Supportted encoder are ascii, bcd and rbcd. Length is
 // required for marshalling and unmarshalling.
@@ -492,14 +1707,26 @@ func NewLllnumeric(val string) *Lllnumeric {
 
 // IsEmpty check Lllnumeric field for empty value
 func (l *Lllnumeric) IsEmpty() bool {
-	return utf8.RuneCountInString(l.Value) == 0
+	return l == nil || utf8.RuneCountInString(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Lllnumeric field
+func (l *Lllnumeric) Copy() Iso8583Type {
+	if l == nil {
+		return (*Lllnumeric)(nil)
+	}
+	c := *l
+	return &c
 }
 
 // Bytes encode Lllnumeric field to bytes
 func (l *Lllnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Lllnumeric"}
+	}
 	raw := []byte(l.Value)
 	if length != -1 && utf8.RuneCount(raw) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Lllnumeric", length, utf8.RuneCount(raw)))
+		return nil, &ErrValueTooLong{"Lllnumeric", length, utf8.RuneCount(raw)}
 	}
 
 	val := raw
@@ -509,8 +1736,13 @@ func (l *Lllnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 		val = lbcd(raw)
 	case rBCD:
 		val = rbcd(raw)
+	case EBCDIC:
+		var err error
+		if val, err = ebcdicEncode(raw); err != nil {
+			return nil, err
+		}
 	default:
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, &ErrInvalidEncoder{encoder}
 	}
 
 	lenStr := fmt.Sprintf("%03d", utf8.RuneCount(raw)) // length of digital characters
@@ -520,23 +1752,31 @@ func (l *Lllnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	case ASCII:
 		lenVal = contentLen
 		if utf8.RuneCount(lenVal) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
+			return nil, &ErrInvalidLengthHead{"Lllnumeric", 999, utf8.RuneCount(raw)}
+		}
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+		if len(lenVal) > 3 {
+			return nil, &ErrInvalidLengthHead{"Lllnumeric", 999, utf8.RuneCount(raw)}
 		}
 	case rBCD:
 		fallthrough
 	case BCD:
 		lenVal = rbcd(contentLen)
 		if utf8.RuneCount(lenVal) > 2 || utf8.RuneCount(contentLen) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
+			return nil, &ErrInvalidLengthHead{"Lllnumeric", 999, utf8.RuneCount(raw)}
 		}
 	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
 	}
 	return append(lenVal, val...), nil
 }
 
 // Load decode Lllnumeric field from bytes
 func (l *Lllnumeric) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Lllnumeric"}
+	}
 	// parse length head:
 	var contentLen int
 	switch lenEncoder {
@@ -544,39 +1784,338 @@ func (l *Lllnumeric) Load(raw []byte, encoder, lenEncoder, length int) (read int
 		read = 3
 		contentLen, err = strconv.Atoi(string(raw[:read]))
 		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:3]))
+			return 0, &ErrParseLength{string(raw[:3])}
+		}
+	case EBCDIC:
+		read = 3
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Lllnumeric", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
 		}
 	case rBCD:
 		fallthrough
 	case BCD:
 		read = 2
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 2)))
+		contentLen, err = bcdLength(raw[:read], 3)
 		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
+			return 0, err
 		}
 	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
 	}
 
 	// parse body:
 	switch encoder {
 	case ASCII:
 		if utf8.RuneCount(raw) < (read + contentLen) {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, &ErrBadRaw{"Lllnumeric", read + contentLen, len(raw)}
 		}
 		l.Value = string(raw[read : read+contentLen])
 		read += contentLen
+	case EBCDIC:
+		if len(raw) < read+contentLen {
+			return 0, &ErrBadRaw{"Lllnumeric", read + contentLen, len(raw)}
+		}
+		val, err := ebcdicDecode(raw[read : read+contentLen])
+		if err != nil {
+			return 0, err
+		}
+		l.Value = string(val)
+		read += contentLen
 	case rBCD:
 		fallthrough
 	case BCD:
 		bcdLen := (contentLen + 1) / 2
 		if utf8.RuneCount(raw) < (read + bcdLen) {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, &ErrBadRaw{"Lllnumeric", read + bcdLen, len(raw)}
 		}
 		l.Value = string(bcdl2Ascii(raw[read:read+bcdLen], contentLen))
 		read += bcdLen
 	default:
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, &ErrInvalidEncoder{encoder}
+	}
+	return read, nil
+}
+
+// Llllvar contains bytes in non-fixed length field of up to 9999 bytes,
+// first 4 symbols (2 bytes in bcd or rbcd) of field contains length
+type Llllvar struct {
+	Value []byte
+}
+
+// NewLlllvar create new Llllvar field
+func NewLlllvar(val []byte) *Llllvar {
+	return &Llllvar{val}
+}
+
+// IsEmpty check Llllvar field for empty value
+func (l *Llllvar) IsEmpty() bool {
+	return l == nil || len(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Llllvar field
+func (l *Llllvar) Copy() Iso8583Type {
+	if l == nil {
+		return (*Llllvar)(nil)
+	}
+	return &Llllvar{copyBytes(l.Value)}
+}
+
+// Bytes encode Llllvar field to bytes
+func (l *Llllvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Llllvar"}
+	}
+	if length != -1 && len(l.Value) > length {
+		return nil, &ErrValueTooLong{"Llllvar", length, len(l.Value)}
+	}
+	if len(l.Value) > 9999 {
+		return nil, &ErrInvalidLengthHead{"Llllvar", 9999, len(l.Value)}
+	}
+	value := l.Value
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		var err error
+		if value, err = ebcdicEncode(l.Value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ErrInvalidEncoder{encoder}
+	}
+
+	contentLen := []byte(fmt.Sprintf("%04d", len(l.Value)))
+	var lenVal []byte
+	switch lenEncoder {
+	case ASCII:
+		lenVal = contentLen
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+	case rBCD:
+		fallthrough
+	case BCD:
+		lenVal = rbcd(contentLen)
+	default:
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	return append(lenVal, value...), nil
+}
+
+// Load decode Llllvar field from bytes. A length head above length, when
+// length is given, is an error.
+func (l *Llllvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Llllvar"}
+	}
+	// parse length head:
+	var contentLen int
+	switch lenEncoder {
+	case ASCII:
+		read = 4
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llllvar", read, len(raw)}
+		}
+		contentLen, err = strconv.Atoi(string(raw[:read]))
+		if err != nil {
+			return 0, &ErrParseLength{string(raw[:read])}
+		}
+	case EBCDIC:
+		read = 4
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llllvar", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
+		}
+	case rBCD:
+		fallthrough
+	case BCD:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llllvar", read, len(raw)}
+		}
+		if contentLen, err = bcdLength(raw[:read], 4); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	if length != -1 && contentLen > length {
+		return 0, &ErrValueTooLong{"Llllvar", length, contentLen}
+	}
+	if contentLen < 0 || len(raw) < read+contentLen {
+		return 0, &ErrBadRaw{"Llllvar", read + contentLen, len(raw)}
+	}
+
+	// parse body:
+	l.Value = raw[read : read+contentLen]
+	switch encoder {
+	case ASCII:
+	case EBCDIC:
+		if l.Value, err = ebcdicDecode(l.Value); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidEncoder{encoder}
+	}
+	return read + contentLen, nil
+}
+
+// A Llllnumeric contains numeric value only in non-fix length of up to 9999
+// digits, contains length in first 4 symbols (2 bytes in bcd or rbcd). It
+// holds numeric value as a string. Supported encoders are ascii, ebcdic, bcd
+// and rbcd; an odd number of digits is padded in the last byte for bcd and
+// in the first byte for rbcd.
+type Llllnumeric struct {
+	Value string
+}
+
+// NewLlllnumeric create new Llllnumeric field
+func NewLlllnumeric(val string) *Llllnumeric {
+	return &Llllnumeric{val}
+}
+
+// IsEmpty check Llllnumeric field for empty value
+func (l *Llllnumeric) IsEmpty() bool {
+	return l == nil || len(l.Value) == 0
+}
+
+// Copy returns a deep copy of the Llllnumeric field
+func (l *Llllnumeric) Copy() Iso8583Type {
+	if l == nil {
+		return (*Llllnumeric)(nil)
+	}
+	c := *l
+	return &c
+}
+
+// Bytes encode Llllnumeric field to bytes
+func (l *Llllnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if l == nil {
+		return nil, &ErrNilField{"Llllnumeric"}
+	}
+	raw := []byte(l.Value)
+	if length != -1 && len(raw) > length {
+		return nil, &ErrValueTooLong{"Llllnumeric", length, len(raw)}
+	}
+	if len(raw) > 9999 {
+		return nil, &ErrInvalidLengthHead{"Llllnumeric", 9999, len(raw)}
+	}
+
+	val := raw
+	switch encoder {
+	case ASCII:
+	case BCD:
+		val = lbcd(raw)
+	case rBCD:
+		val = rbcd(raw)
+	case EBCDIC:
+		var err error
+		if val, err = ebcdicEncode(raw); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ErrInvalidEncoder{encoder}
+	}
+
+	contentLen := []byte(fmt.Sprintf("%04d", len(raw))) // length of digital characters
+	var lenVal []byte
+	switch lenEncoder {
+	case ASCII:
+		lenVal = contentLen
+	case EBCDIC:
+		lenVal = ebcdicDigits(contentLen)
+	case rBCD:
+		fallthrough
+	case BCD:
+		lenVal = rbcd(contentLen)
+	default:
+		return nil, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	return append(lenVal, val...), nil
+}
+
+// Load decode Llllnumeric field from bytes
+func (l *Llllnumeric) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if l == nil {
+		return 0, &ErrNilField{"Llllnumeric"}
+	}
+	// parse length head:
+	var contentLen int
+	switch lenEncoder {
+	case ASCII:
+		read = 4
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llllnumeric", read, len(raw)}
+		}
+		contentLen, err = strconv.Atoi(string(raw[:read]))
+		if err != nil {
+			return 0, &ErrParseLength{string(raw[:read])}
+		}
+	case EBCDIC:
+		read = 4
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llllnumeric", read, len(raw)}
+		}
+		if contentLen, err = ebcdicLength(raw[:read]); err != nil {
+			return 0, err
+		}
+	case rBCD:
+		fallthrough
+	case BCD:
+		read = 2
+		if len(raw) < read {
+			return 0, &ErrBadRaw{"Llllnumeric", read, len(raw)}
+		}
+		if contentLen, err = bcdLength(raw[:read], 4); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, &ErrInvalidLengthEncoder{lenEncoder}
+	}
+	if length != -1 && contentLen > length {
+		return 0, &ErrValueTooLong{"Llllnumeric", length, contentLen}
+	}
+	if contentLen < 0 {
+		return 0, &ErrBadRaw{"Llllnumeric", read + contentLen, len(raw)}
+	}
+
+	// parse body:
+	switch encoder {
+	case ASCII:
+		if len(raw) < read+contentLen {
+			return 0, &ErrBadRaw{"Llllnumeric", read + contentLen, len(raw)}
+		}
+		l.Value = string(raw[read : read+contentLen])
+		read += contentLen
+	case EBCDIC:
+		if len(raw) < read+contentLen {
+			return 0, &ErrBadRaw{"Llllnumeric", read + contentLen, len(raw)}
+		}
+		val, err := ebcdicDecode(raw[read : read+contentLen])
+		if err != nil {
+			return 0, err
+		}
+		l.Value = string(val)
+		read += contentLen
+	case BCD:
+		bcdLen := (contentLen + 1) / 2
+		if len(raw) < read+bcdLen {
+			return 0, &ErrBadRaw{"Llllnumeric", read + bcdLen, len(raw)}
+		}
+		l.Value = string(bcdl2Ascii(raw[read:read+bcdLen], contentLen))
+		read += bcdLen
+	case rBCD:
+		bcdLen := (contentLen + 1) / 2
+		if len(raw) < read+bcdLen {
+			return 0, &ErrBadRaw{"Llllnumeric", read + bcdLen, len(raw)}
+		}
+		l.Value = string(bcdr2Ascii(raw[read:read+bcdLen], contentLen))
+		read += bcdLen
+	default:
+		return 0, &ErrInvalidEncoder{encoder}
 	}
 	return read, nil
 }
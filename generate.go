@@ -0,0 +1,89 @@
+package iso8583
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var stan uint32
+
+// NextSTAN returns the next value of the package-level systems trace audit
+// number counter, formatted as 6 digits. It wraps from 999999 to 000001 and
+// is safe for concurrent use.
+func NextSTAN() string {
+	for {
+		cur := atomic.LoadUint32(&stan)
+		next := cur%999999 + 1
+		if atomic.CompareAndSwapUint32(&stan, cur, next) {
+			return fmt.Sprintf("%06d", next)
+		}
+	}
+}
+
+// AddGeneratedFields sets the transmission date and time (7), STAN (11),
+// local time (12) and local date (13) from the current time and NextSTAN.
+// Only fields defined in Data and not already set are generated; Data must
+// be a pointer to struct.
+func (m *Message) AddGeneratedFields() error {
+	fields, err := m.fields()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	generated := []struct {
+		n     int
+		value func() string
+	}{
+		{7, func() string { return now.UTC().Format("0102150405") }},
+		{11, NextSTAN},
+		{12, func() string { return now.Format("150405") }},
+		{13, func() string { return now.Format("0102") }},
+	}
+	for _, g := range generated {
+		if info, ok := fields[g.n]; ok && !info.Field.IsEmpty() {
+			continue
+		}
+		if _, err := structField(m.Data, g.n); err != nil {
+			// the field is not part of this message
+			continue
+		}
+		if err := setFieldString(m.Data, g.n, g.value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timestampFormats are the time layouts of the date and time fields set by
+// InjectNow
+var timestampFormats = map[int]string{
+	7:  "0102150405",
+	12: "150405",
+	13: "0102",
+	15: "0102",
+	17: "0106",
+}
+
+// InjectNow sets the listed timestamp fields to the current UTC time, as a
+// refresh before a cloned message is sent again: 7 as MMDDhhmmss, 12 as
+// hhmmss, 13 and 15 as MMDD and 17 as MMYY. Other field numbers are an
+// error and leave the message unchanged.
+func (m *Message) InjectNow(fields ...int) (*Message, error) {
+	return m.injectNow(time.Now().UTC(), fields)
+}
+
+func (m *Message) injectNow(now time.Time, fields []int) (*Message, error) {
+	for _, n := range fields {
+		if _, ok := timestampFormats[n]; !ok {
+			return m, fmt.Errorf("field %d is not a timestamp field", n)
+		}
+	}
+	for _, n := range fields {
+		if err := setFieldString(m.Data, n, now.Format(timestampFormats[n])); err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,98 @@
+package iso8583
+
+import (
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddGeneratedFields(t *testing.T) {
+	data := &TestISO{}
+	iso := NewMessage("0200", data)
+	assert.Nil(t, iso.AddGeneratedFields())
+
+	assert.Regexp(t, regexp.MustCompile(`^\d{10}$`), data.F7.Value)
+	assert.Regexp(t, regexp.MustCompile(`^\d{6}$`), data.F11.Value)
+	assert.Regexp(t, regexp.MustCompile(`^\d{6}$`), data.F12.Value)
+	assert.Regexp(t, regexp.MustCompile(`^\d{4}$`), data.F13.Value)
+	assert.NotEqual(t, "000000", data.F11.Value)
+
+	_, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	// fields set by the application are kept
+	data = &TestISO{F7: NewNumeric("0701111844"), F11: NewNumeric("000123")}
+	iso = NewMessage("0200", data)
+	assert.Nil(t, iso.AddGeneratedFields())
+	assert.Equal(t, "0701111844", data.F7.Value)
+	assert.Equal(t, "000123", data.F11.Value)
+	assert.NotNil(t, data.F12)
+	assert.NotNil(t, data.F13)
+
+	// fields missing from the template are skipped
+	type onlySTAN struct {
+		F11 *Numeric `field:"11" length:"6"`
+	}
+	iso = NewMessage("0200", &onlySTAN{})
+	assert.Nil(t, iso.AddGeneratedFields())
+	assert.NotNil(t, iso.Data.(*onlySTAN).F11)
+}
+
+func TestNextSTAN(t *testing.T) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := NextSTAN()
+				mu.Lock()
+				seen[s] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 800)
+
+	saved := atomic.LoadUint32(&stan)
+	t.Cleanup(func() { atomic.StoreUint32(&stan, saved) })
+	atomic.StoreUint32(&stan, 999999)
+	assert.Equal(t, "000001", NextSTAN())
+}
+
+func TestInjectNow(t *testing.T) {
+	type dates struct {
+		F7  *Numeric `field:"7" length:"10" encode:"ascii"`
+		F12 *Numeric `field:"12" length:"6" encode:"ascii"`
+		F13 *Numeric `field:"13" length:"4" encode:"ascii"`
+		F15 *Numeric `field:"15" length:"4" encode:"ascii"`
+		F17 *Numeric `field:"17" length:"4" encode:"ascii"`
+	}
+	data := &dates{F7: NewNumeric("0101000000")}
+	msg := NewMessage("0200", data)
+	now := time.Date(2021, 7, 9, 14, 5, 30, 0, time.UTC)
+	ret, err := msg.injectNow(now, []int{7, 12, 13, 15, 17})
+	assert.NoError(t, err)
+	assert.Equal(t, msg, ret)
+	assert.Equal(t, "0709140530", data.F7.Value)
+	assert.Equal(t, "140530", data.F12.Value)
+	assert.Equal(t, "0709", data.F13.Value)
+	assert.Equal(t, "0709", data.F15.Value)
+	assert.Equal(t, "0721", data.F17.Value)
+
+	_, err = msg.InjectNow(7, 12)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^\d{10}$`), data.F7.Value)
+
+	data = &dates{}
+	_, err = NewMessage("0200", data).InjectNow(7, 11)
+	assert.EqualError(t, err, "field 11 is not a timestamp field")
+	assert.Nil(t, data.F7)
+}
@@ -0,0 +1,82 @@
+package iso8583
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetHexDigits sets a value of hex digits (0-9, A-F, in either case). The
+// BCD encoders pack hex digits as nibbles verbatim.
+func (n *Numeric) SetHexDigits(s string) error {
+	if n == nil {
+		return &ErrNilField{"Numeric"}
+	}
+	if err := checkHexDigits("Numeric", s); err != nil {
+		return err
+	}
+	*n = Numeric{Value: s}
+	return nil
+}
+
+// HexDigits returns the value with hex digits in uppercase. BCD decoding
+// yields them in lowercase.
+func (n *Numeric) HexDigits() string {
+	if n == nil {
+		return ""
+	}
+	return strings.ToUpper(n.Digits())
+}
+
+// SetHexDigits sets a value of hex digits (0-9, A-F, in either case). The
+// BCD encoders pack hex digits as nibbles verbatim.
+func (l *Llnumeric) SetHexDigits(s string) error {
+	if l == nil {
+		return &ErrNilField{"Llnumeric"}
+	}
+	if err := checkHexDigits("Llnumeric", s); err != nil {
+		return err
+	}
+	l.Value = s
+	return nil
+}
+
+// HexDigits returns the value with hex digits in uppercase. BCD decoding
+// yields them in lowercase.
+func (l *Llnumeric) HexDigits() string {
+	if l == nil {
+		return ""
+	}
+	return strings.ToUpper(l.Value)
+}
+
+// SetHexDigits sets a value of hex digits (0-9, A-F, in either case). The
+// BCD encoders pack hex digits as nibbles verbatim.
+func (l *Lllnumeric) SetHexDigits(s string) error {
+	if l == nil {
+		return &ErrNilField{"Lllnumeric"}
+	}
+	if err := checkHexDigits("Lllnumeric", s); err != nil {
+		return err
+	}
+	l.Value = s
+	return nil
+}
+
+// HexDigits returns the value with hex digits in uppercase. BCD decoding
+// yields them in lowercase.
+func (l *Lllnumeric) HexDigits() string {
+	if l == nil {
+		return ""
+	}
+	return strings.ToUpper(l.Value)
+}
+
+func checkHexDigits(typ, s string) error {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'F' || c >= 'a' && c <= 'f') {
+			return fmt.Errorf("invalid hex digit %q at %d in %s value", c, i, typ)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexDigits(t *testing.T) {
+	type data struct {
+		F3  *Numeric    `field:"3" length:"6" encode:"bcd"`
+		F19 *Numeric    `field:"19" length:"3" encode:"rbcd"`
+		F32 *Llnumeric  `field:"32" length:"11" encode:"bcd,bcd"`
+		F62 *Lllnumeric `field:"62" length:"999" encode:"bcd,bcd"`
+	}
+	d := &data{F3: &Numeric{}, F19: &Numeric{}, F32: &Llnumeric{}, F62: &Lllnumeric{}}
+	assert.Nil(t, d.F3.SetHexDigits("12AF00"))
+	assert.Nil(t, d.F19.SetHexDigits("A0F"))
+	assert.Nil(t, d.F32.SetHexDigits("1FA"))
+	assert.Nil(t, d.F62.SetHexDigits("ABCDEF"))
+
+	b, err := NewMessage("0200", d).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x12, 0xAF, 0x00}, b[12:15])
+	assert.Equal(t, []byte{0x0A, 0x0F}, b[15:17])
+
+	parsed := &data{F3: &Numeric{}, F19: &Numeric{}, F32: &Llnumeric{}, F62: &Lllnumeric{}}
+	assert.Nil(t, NewMessage("", parsed).Load(b))
+	assert.Equal(t, "12AF00", parsed.F3.HexDigits())
+	assert.Equal(t, "A0F", parsed.F19.HexDigits())
+	assert.Equal(t, "1FA", parsed.F32.HexDigits())
+	assert.Equal(t, "ABCDEF", parsed.F62.HexDigits())
+
+	assert.EqualError(t, d.F3.SetHexDigits("12G4"), `invalid hex digit 'G' at 2 in Numeric value`)
+	assert.Equal(t, "12AF00", d.F3.Value)
+}
@@ -0,0 +1,82 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+var _ io.WriterTo = (*Message)(nil)
+
+// WriteTo packs the message and writes it to w without framing. It
+// implements io.WriterTo; the message carries its own layout in Data, so no
+// separate spec is needed.
+func (m *Message) WriteTo(w io.Writer) (int64, error) {
+	b, err := m.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ValidateFraming checks that raw is exactly one well-formed message for
+// p: the MTI is registered, every field in the bitmap is defined and
+// decodes, and the fields consume the whole buffer. It is meant for
+// recovery code testing candidate message boundaries. Only the layout
+// settings of p are used: its callbacks, faults and strictness checks are
+// not.
+func ValidateFraming(raw []byte, p *Parser) error {
+	if p == nil {
+		return errors.New("parser is required")
+	}
+	q := Parser{
+		messages:        p.messages,
+		MtiEncode:       p.MtiEncode,
+		Presence:        p.Presence,
+		Encoder:         p.Encoder,
+		SecondaryBitmap: p.SecondaryBitmap,
+	}
+	_, end, _, err := q.parse(raw)
+	if err != nil {
+		return err
+	}
+	if end != len(raw) {
+		return fmt.Errorf("message ends at byte %d of %d", end, len(raw))
+	}
+	return nil
+}
+
+// ReadFrom reads r to its end and parses the bytes as one message with p.
+// Use FramedReadFrom for connections carrying several messages.
+func ReadFrom(r io.Reader, p *Parser) (*Message, error) {
+	if p == nil {
+		return nil, errors.New("parser is required")
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse(b)
+}
+
+// FramedReadFrom reads one message framed with a big-endian length head of
+// frameLen (1 to 4) bytes from r and parses it with p. Partial reads are
+// completed, so r can be a net.Conn.
+func FramedReadFrom(r io.Reader, p *Parser, frameLen int) (*Message, error) {
+	if p == nil {
+		return nil, errors.New("parser is required")
+	}
+	b, err := readFrame(r, frameLen, p.MaxMessageSize)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse(b)
+}
+
+// ReadFromBuffer parses the unframed message in buf with p
+func ReadFromBuffer(buf []byte, p *Parser) (*Message, error) {
+	return ReadFrom(bytes.NewReader(buf), p)
+}
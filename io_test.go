@@ -0,0 +1,197 @@
+package iso8583
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shortWriter struct {
+	max int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.max {
+		return w.max, errors.New("short write")
+	}
+	return len(p), nil
+}
+
+func TestMessageWriteTo(t *testing.T) {
+	iso := NewMessage("0200", &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F41: NewAlphanumeric("TERM0001"),
+	})
+	expected, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	n, err := iso.WriteTo(w)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, int64(len(expected)), n)
+	assert.Equal(t, expected, buf.Bytes())
+
+	n, err = iso.WriteTo(&shortWriter{10})
+	assert.EqualError(t, err, "short write")
+	assert.Equal(t, int64(10), n)
+
+	iso.Data.(*TestISO).F3.Value = "1234567"
+	n, err = iso.WriteTo(&buf)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(0), n)
+}
+
+func TestValidateFraming(t *testing.T) {
+	type data struct {
+		F3  *Numeric `field:"3" length:"6"`
+		F54 *Llvar   `field:"54" length:"99"`
+	}
+	parser := &Parser{}
+	assert.Nil(t, parser.Register("0200", &data{}))
+
+	first, err := NewMessage("0200", &data{F3: NewNumeric("000000"), F54: NewLlvar([]byte("05ABCDE"))}).Bytes()
+	assert.Nil(t, err)
+	assert.Nil(t, ValidateFraming(first, parser))
+
+	second, err := NewMessage("0200", &data{F3: NewNumeric("000000"), F54: NewLlvar([]byte("XY"))}).Bytes()
+	assert.Nil(t, err)
+
+	// two messages read as one still parse, the second is ignored
+	joined := append(append([]byte(nil), first...), second...)
+	_, err = parser.Parse(joined)
+	assert.Nil(t, err)
+	assert.EqualError(t, ValidateFraming(joined, parser), "message ends at byte 27 of 49")
+
+	// a boundary cutting into the value of field 54
+	assert.NotNil(t, ValidateFraming(first[:len(first)-3], parser))
+
+	assert.EqualError(t, ValidateFraming([]byte("0800"), parser), "no template registered for MTI: 0800")
+	assert.EqualError(t, ValidateFraming(first, nil), "parser is required")
+
+	// the callback of the parser is not triggered
+	parser.OnField = func(n int, f Iso8583Type, raw []byte) { t.Error("OnField called") }
+	assert.Nil(t, ValidateFraming(first, parser))
+}
+
+func TestValidateFramingIgnoresChecks(t *testing.T) {
+	type data struct {
+		F2  *Llnumeric `field:"2" length:"19"`
+		F18 *Numeric   `field:"18" length:"4"`
+		F35 *Llnumeric `field:"35" length:"37"`
+	}
+	parser := &Parser{StrictMCC: true, StrictCardData: true, RetainRaw: true}
+	assert.Nil(t, parser.Register("0200", &data{}))
+
+	raw, err := NewMessage("0200", &data{
+		F2:  NewLlnumeric("4276555555555555"),
+		F18: NewNumeric("0000"),
+		F35: NewLlnumeric("4276555555555556"),
+	}).Bytes()
+	assert.Nil(t, err)
+	_, err = parser.Parse(raw)
+	assert.NotNil(t, err)
+	assert.Nil(t, ValidateFraming(raw, parser))
+
+	parser.StrictCardData = false
+	_, err = parser.Parse(raw)
+	assert.True(t, errors.Is(err, ErrUnknownMCC))
+	assert.Nil(t, ValidateFraming(raw, parser))
+
+	parser.StrictMCC = false
+	parser.Faults = truncateFaults{len(raw) - 1}
+	_, err = parser.Parse(raw)
+	assert.NotNil(t, err)
+	assert.Nil(t, ValidateFraming(raw, parser))
+}
+
+func TestValidateFramingEncoder(t *testing.T) {
+	type data struct {
+		F3  *Numeric `field:"3" length:"6"`
+		F54 *Llvar   `field:"54" length:"99"`
+	}
+	parser := &Parser{Encoder: bitmapFirst{}}
+	assert.Nil(t, parser.Register("0200", &data{}))
+
+	raw, err := NewMessage("0200", &data{F3: NewNumeric("000000"), F54: NewLlvar([]byte("ABCDE"))}).
+		WithEncoder(bitmapFirst{}).Bytes()
+	assert.Nil(t, err)
+	assert.Nil(t, ValidateFraming(raw, parser))
+
+	long := append(append([]byte(nil), raw...), "0200"...)
+	assert.EqualError(t, ValidateFraming(long, parser), "message ends at byte 25 of 29")
+}
+
+func TestReadFrom(t *testing.T) {
+	parser := &Parser{}
+	assert.Nil(t, parser.Register("0200", &TestISO{}))
+	iso := NewMessage("0200", &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F41: NewAlphanumeric("TERM0001"),
+	})
+	raw, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	msg, err := ReadFromBuffer(raw, parser)
+	assert.Nil(t, err)
+	assert.Equal(t, "TERM0001", msg.Data.(*TestISO).F41.Value)
+
+	// the message arrives in pieces
+	r, w := io.Pipe()
+	go func() {
+		frame := append([]byte{0, 0, 0, byte(len(raw))}, raw...)
+		for i := 0; i < len(frame); i += 3 {
+			end := i + 3
+			if end > len(frame) {
+				end = len(frame)
+			}
+			w.Write(frame[i:end])
+		}
+		iso.WriteTo(w)
+		w.Close()
+	}()
+	msg, err = FramedReadFrom(r, parser, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, "4276555555555555", msg.Data.(*TestISO).F2.Value)
+	msg, err = ReadFrom(r, parser)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000", msg.Data.(*TestISO).F3.Value)
+
+	_, err = FramedReadFrom(bytes.NewReader([]byte{0, 50, '0', '2'}), parser, 2)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = FramedReadFrom(bytes.NewReader(raw), parser, 5)
+	assert.EqualError(t, err, "invalid frame head length 5")
+	_, err = ReadFrom(bytes.NewReader(raw), nil)
+	assert.EqualError(t, err, "parser is required")
+}
+
+func TestFramedReadFromLimit(t *testing.T) {
+	parser := &Parser{}
+	assert.Nil(t, parser.Register("0200", &TestISO{}))
+	raw, err := NewMessage("0200", &TestISO{F3: NewNumeric("000000")}).Bytes()
+	assert.Nil(t, err)
+	frame := append([]byte{0, byte(len(raw))}, raw...)
+
+	// a huge head fails before anything is allocated for it
+	_, err = FramedReadFrom(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF}), parser, 4)
+	assert.EqualError(t, err, "message is 4294967295 bytes, limit is 1048576")
+
+	parser.MaxMessageSize = len(raw) - 1
+	_, err = FramedReadFrom(bytes.NewReader(frame), parser, 2)
+	var tooLarge *ErrMessageTooLarge
+	assert.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, len(raw), tooLarge.Size)
+	_, err = parser.Parse(raw)
+	assert.True(t, errors.As(err, &tooLarge))
+
+	parser.MaxMessageSize = len(raw)
+	_, err = FramedReadFrom(bytes.NewReader(frame), parser, 2)
+	assert.Nil(t, err)
+}
@@ -2,8 +2,11 @@ package iso8583
 
 import (
 	"bytes"
-	"github.com/stretchr/testify/assert"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 type TestISO struct {
@@ -95,7 +98,7 @@ func TestEncode(t *testing.T) {
 		F120: NewLllnumeric("Another test text"),
 	}
 
-	iso := Message{"0100", ASCII, true, data}
+	iso := Message{Mti: "0100", MtiEncode: ASCII, SecondBitmap: true, Data: data}
 
 	res, err := iso.Bytes()
 
@@ -115,7 +118,7 @@ func TestDecode(t *testing.T) {
 	input := []byte{48, 49, 48, 48, 242, 60, 36, 129, 40, 224, 152, 0, 0, 0, 0, 0, 0, 0, 1, 0, 49, 54, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 55, 55, 55, 48, 48, 48, 55, 48, 49, 49, 49, 49, 56, 52, 52, 48, 48, 48, 49, 50, 51, 49, 51, 49, 56, 52, 52, 48, 55, 48, 49, 49, 57, 48, 50, 6, 67, 57, 48, 49, 48, 50, 48, 54, 49, 50, 51, 52, 53, 54, 51, 55, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 61, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 48, 49, 48, 48, 48, 48, 48, 51, 50, 49, 49, 50, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 51, 52, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 84, 101, 115, 116, 32, 116, 101, 120, 116, 100, 48, 1, 2, 3, 4, 5, 6, 7, 8, 49, 50, 51, 52, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 49, 55, 65, 110, 111, 116, 104, 101, 114, 32, 116, 101, 115, 116, 32, 116, 101, 120, 116}
 
 	// init empty iso message struct
-	iso := Message{"", ASCII, true, newDataIso()}
+	iso := Message{Mti: "", MtiEncode: ASCII, SecondBitmap: true, Data: newDataIso()}
 
 	// parse data from bytes to iso struct
 	err := iso.Load(input)
@@ -219,7 +222,7 @@ func TestEncodeDecode(t *testing.T) {
 		F63: NewLllnumeric("123abc456ef7890"),
 	}
 
-	iso := Message{"0110", ASCII, false, data}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data}
 
 	res, err := iso.Bytes()
 
@@ -227,7 +230,7 @@ func TestEncodeDecode(t *testing.T) {
 		t.Error("ISO Encode error:", err)
 	}
 
-	iso2 := Message{"0110", ASCII, false, data}
+	iso2 := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data}
 
 	err = iso2.Load(res)
 
@@ -249,7 +252,7 @@ func TestFieldNumericEncodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -263,7 +266,7 @@ func TestFieldNumericEncodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -277,7 +280,7 @@ func TestFieldNumericEncodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -294,7 +297,7 @@ func TestFieldAlphanumericEncodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("abcdef"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -308,7 +311,7 @@ func TestFieldAlphanumericEncodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("abcdef"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -325,7 +328,7 @@ func TestFieldBinaryEncodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("abcdef")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -339,7 +342,7 @@ func TestFieldBinaryEncodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("abcdef")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -356,7 +359,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -370,7 +373,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -384,7 +387,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric(string(bytes.Repeat([]byte("a"), 100))),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -398,7 +401,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric(string(bytes.Repeat([]byte("a"), 100))),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -412,7 +415,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -429,7 +432,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -443,7 +446,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -457,7 +460,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric(string(bytes.Repeat([]byte("a"), 1000))),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -471,7 +474,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric(string(bytes.Repeat([]byte("a"), 1000))),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -485,7 +488,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -502,7 +505,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -516,7 +519,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -530,7 +533,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar(bytes.Repeat([]byte("a"), 100)),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -544,7 +547,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar(bytes.Repeat([]byte("a"), 100)),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -558,7 +561,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -575,7 +578,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -589,7 +592,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -603,7 +606,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar(bytes.Repeat([]byte("a"), 1000)),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -617,7 +620,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar(bytes.Repeat([]byte("a"), 1000)),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -631,7 +634,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -647,7 +650,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -667,7 +670,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -687,7 +690,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -707,7 +710,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -721,7 +724,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	err = iso.Load(isoBytes)
 
@@ -737,7 +740,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -757,7 +760,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -777,7 +780,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -797,7 +800,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -811,7 +814,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -821,7 +824,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test6 struct {
 		F2 *Llnumeric `field:"2" length:"10" encode:"rbcd,ascii"`
@@ -831,11 +834,11 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test7 struct {
 		F2 *Llnumeric `field:"2" length:"10" encode:"ascii,ascii"`
@@ -845,7 +848,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -866,7 +869,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -882,7 +885,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -902,7 +905,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -922,7 +925,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -942,7 +945,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -956,7 +959,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -967,7 +970,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {}")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test6 struct {
 		F2 *Lllnumeric `field:"2" length:"10" encode:"rbcd,ascii"`
@@ -977,11 +980,11 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {}")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test7 struct {
 		F2 *Lllnumeric `field:"2" length:"10" encode:"ascii,ascii"`
@@ -991,7 +994,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1013,7 +1016,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -1029,7 +1032,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1049,7 +1052,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1069,7 +1072,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1089,7 +1092,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -1103,7 +1106,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1113,7 +1116,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test6 struct {
 		F2 *Llvar `field:"2" length:"10" encode:"rbcd,ascii"`
@@ -1123,11 +1126,11 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test7 struct {
 		F2 *Llvar `field:"2" length:"10" encode:"ascii,ascii"`
@@ -1137,7 +1140,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1158,7 +1161,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -1174,7 +1177,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1194,7 +1197,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1214,7 +1217,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1234,7 +1237,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -1248,7 +1251,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1259,7 +1262,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {}")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test6 struct {
 		F2 *Lllvar `field:"2" length:"10" encode:"rbcd,ascii"`
@@ -1269,11 +1272,11 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
-	assert.EqualError(t, err, "field 2: parse length head failed: {}")
+	assert.EqualError(t, err, "field 2: parse length head failed: invalid BCD byte 0x7b at nibble 1")
 
 	type test7 struct {
 		F2 *Lllvar `field:"2" length:"10" encode:"ascii,ascii"`
@@ -1283,7 +1286,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1305,7 +1308,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -1321,7 +1324,7 @@ func TestFieldAlphanumericDecodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1341,7 +1344,7 @@ func TestFieldAlphanumericDecodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	err = iso.Load(isoBytes)
 
@@ -1357,7 +1360,7 @@ func TestFieldBinaryDecodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1377,7 +1380,7 @@ func TestFieldBinaryDecodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	err = iso.Load(isoBytes)
 
@@ -1510,7 +1513,7 @@ func TestMessage(t *testing.T) {
 		AB *Llnumeric `field:"ab" length:"19"`
 	}
 
-	iso := Message{"", ASCII, true, TestIso{*newDataIso(), NewLlnumeric("")}}
+	iso := Message{Mti: "", MtiEncode: ASCII, SecondBitmap: true, Data: TestIso{*newDataIso(), NewLlnumeric("")}}
 
 	input := []byte{48, 49, 48, 48, 114, 60, 36, 129, 40, 224, 152, 0, 49, 54, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 55, 55, 55, 48, 48, 48, 55, 48, 49, 49, 49, 49, 56, 52, 52, 48, 48, 48, 49, 50, 51, 49, 51, 49, 56, 52, 52, 48, 55, 48, 49, 49, 57, 48, 50, 6, 67, 57, 48, 49, 48, 50, 48, 54, 49, 50, 51, 52, 53, 54, 51, 55, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 61, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 48, 49, 48, 48, 48, 48, 48, 51, 50, 49, 49, 50, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 51, 52, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 84, 101, 115, 116, 32, 116, 101, 120, 116, 100, 48, 1, 2, 3, 4, 5, 6, 7, 8, 49, 50, 51, 52, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48}
 
@@ -1522,7 +1525,7 @@ func TestMessage(t *testing.T) {
 		F2 *Llnumeric `field:"2" length:"19"`
 	}
 
-	iso = Message{"", ASCII, true, TestIso2{}}
+	iso = Message{Mti: "", MtiEncode: ASCII, SecondBitmap: true, Data: TestIso2{}}
 
 	err = iso.Load(input)
 
@@ -1535,7 +1538,7 @@ func TestMTIError(t *testing.T) {
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso := Message{"01000", ASCII, true, data}
+	iso := Message{Mti: "01000", MtiEncode: ASCII, SecondBitmap: true, Data: data}
 
 	_, err := iso.Bytes()
 
@@ -1553,13 +1556,13 @@ func TestMTIError(t *testing.T) {
 
 	assert.EqualError(t, err, "MTI is required")
 
-	iso = Message{"0100", BCD, true, data}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	res, err := iso.Bytes()
 
 	assert.Empty(t, err)
 
-	iso = Message{"", BCD, true, data}
+	iso = Message{Mti: "", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	err = iso.Load(res[0:1])
 
@@ -1578,13 +1581,13 @@ func TestMTIBCD(t *testing.T) {
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso := Message{"0100", BCD, true, data}
+	iso := Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	res, err := iso.Bytes()
 
 	assert.Empty(t, err)
 
-	iso2 := Message{"0100", BCD, true, data}
+	iso2 := Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	err = iso2.Load(res)
 
@@ -1602,7 +1605,7 @@ func TestParseFieldsErrors(t *testing.T) {
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso := Message{"0100", BCD, true, data1}
+	iso := Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -1616,7 +1619,7 @@ func TestParseFieldsErrors(t *testing.T) {
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso = Message{"0100", BCD, true, data2}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -1630,13 +1633,13 @@ func TestParseFieldsErrors(t *testing.T) {
 		F2: string("123abc"),
 	}
 
-	iso = Message{"0100", BCD, true, data3}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data3}
 
 	_, err = iso.Bytes()
 
 	assert.EqualError(t, err, "Critical error:field must be Iso8583Type")
 
-	iso = Message{"0100", BCD, true, nil}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: nil}
 
 	_, err = iso.Bytes()
 
@@ -1670,3 +1673,1447 @@ func newDataIso() *TestISO {
 		F120: NewLllnumeric(""),
 	}
 }
+
+func TestMessageClone(t *testing.T) {
+	data := &TestISO2{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F28: NewAlphanumeric("abcd12345"),
+		F52: NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+		F54: NewLlvar([]byte("llvar")),
+		F56: NewLllvar([]byte("lllvar")),
+	}
+	iso := NewMessage("0100", data)
+
+	clone := iso.Clone()
+
+	orig, err := iso.Bytes()
+	assert.Nil(t, err)
+	res, err := clone.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, orig, res)
+
+	cloneData := clone.Data.(*TestISO2)
+	cloneData.F2.Value = "4111111111111111"
+	cloneData.F52.Value[0] = 9
+	cloneData.F54.Value[0] = 'L'
+	cloneData.F56.Value = []byte("changed")
+	clone.Mti = "0110"
+
+	assert.Equal(t, "0100", iso.Mti)
+	assert.Equal(t, "4276555555555555", data.F2.Value)
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, data.F52.Value)
+	assert.Equal(t, []byte("llvar"), data.F54.Value)
+	assert.Equal(t, []byte("lllvar"), data.F56.Value)
+	assert.Nil(t, cloneData.F4)
+
+	res, err = iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, orig, res)
+}
+
+func TestAlphanumericPadStripping(t *testing.T) {
+	a := NewAlphanumericNullPad("")
+	read, err := a.Load([]byte("ABC\x00\x00\x00"), ASCII, ASCII, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, read)
+	assert.Equal(t, "ABC", a.Value)
+
+	a = &Alphanumeric{PadChar: 0xFF}
+	read, err = a.Load([]byte("ABC\xff\xff\xffDEF"), ASCII, ASCII, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, read)
+	assert.Equal(t, "ABC", a.Value)
+
+	// values without padding are unaffected
+	a = NewAlphanumericNullPad("")
+	_, err = a.Load([]byte("ABCDEF"), ASCII, ASCII, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, "ABCDEF", a.Value)
+
+	// nulls are kept by default
+	a = NewAlphanumeric("")
+	_, err = a.Load([]byte("ABC\x00\x00\x00"), ASCII, ASCII, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC\x00\x00\x00", a.Value)
+}
+
+func TestLengthHeadWidth(t *testing.T) {
+	value := strings.Repeat("1", 45)
+
+	// encode always emits the full declared head width
+	res, err := NewLlvar([]byte("12345")).Bytes(ASCII, ASCII, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("0512345"), res)
+
+	res, err = NewLlvar([]byte("12345")).Bytes(ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("\x0512345"), res)
+
+	res, err = NewLllvar([]byte(value)).Bytes(ASCII, ASCII, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("045"), res[:3])
+
+	res, err = NewLllvar([]byte(value)).Bytes(ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x45}, res[:2])
+
+	res, err = NewLlnumeric("5").Bytes(ASCII, ASCII, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("015"), res)
+
+	res, err = NewLllnumeric("5").Bytes(ASCII, ASCII, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("0015"), res)
+
+	res, err = NewLllnumeric("5").Bytes(ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x01, '5'}, res)
+
+	// decode reads exactly the head width
+	lllvar := NewLllvar(nil)
+	read, err := lllvar.Load([]byte("045"+value+"tail"), ASCII, ASCII, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 48, read)
+	assert.Equal(t, []byte(value), lllvar.Value)
+
+	llvar := NewLlvar(nil)
+	read, err = llvar.Load([]byte("\x0512345tail"), ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, read)
+	assert.Equal(t, []byte("12345"), llvar.Value)
+
+	// all three digits of a 2 byte BCD head are significant
+	long := strings.Repeat("9", 123)
+	res, err = NewLllnumeric(long).Bytes(ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x01, 0x23}, res[:2])
+	lllnumeric := NewLllnumeric("")
+	read, err = lllnumeric.Load(res, ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 125, read)
+	assert.Equal(t, long, lllnumeric.Value)
+}
+
+func TestMessageRetainRaw(t *testing.T) {
+	data := &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F37: NewAlphanumeric("987654321001"),
+	}
+	input, err := NewMessage("0100", data).Bytes()
+	assert.Nil(t, err)
+
+	buf := append([]byte(nil), input...)
+
+	parser := Parser{}
+	assert.Nil(t, parser.Register("0100", &TestISO{}))
+	msg, err := parser.Parse(buf)
+	assert.Nil(t, err)
+	assert.Nil(t, msg.Raw())
+
+	parser.RetainRaw = true
+	msg, err = parser.Parse(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, input, msg.Raw())
+
+	// reuse the read buffer
+	for i := range buf {
+		buf[i] = 0
+	}
+	assert.Equal(t, input, msg.Raw())
+
+	assert.Nil(t, msg.Clone().Raw())
+}
+
+func TestMessageCopyField(t *testing.T) {
+	src := NewMessage("0200", &TestISO2{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F52: NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+		F54: NewLlvar([]byte("llvar")),
+	})
+	dstData := &TestISO2{}
+	dst := NewMessage("0210", dstData)
+
+	assert.Nil(t, src.CopyField(4, dst, 4))
+	assert.Equal(t, "000000077700", dstData.F4.Value)
+
+	// copy to a different field number of the same type
+	assert.Nil(t, src.CopyField(54, dst, 55))
+	assert.Equal(t, []byte("llvar"), dstData.F55.Value)
+	src.Data.(*TestISO2).F54.Value[0] = 'L'
+	assert.Equal(t, []byte("llvar"), dstData.F55.Value)
+
+	err := src.CopyField(11, dst, 11)
+	assert.True(t, errors.Is(err, ErrFieldNotSet))
+	assert.EqualError(t, err, "field 11: field not set")
+
+	err = src.CopyField(4, dst, 54)
+	assert.EqualError(t, err, "field 54: cannot copy *iso8583.Numeric into *iso8583.Llvar")
+
+	err = src.CopyField(4, dst, 5)
+	assert.EqualError(t, err, "field 5 not defined")
+
+	assert.Nil(t, src.CopyFields(map[int]int{2: 2, 3: 3, 52: 52}, dst))
+	assert.Equal(t, "4276555555555555", dstData.F2.Value)
+	assert.Equal(t, "000000", dstData.F3.Value)
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, dstData.F52.Value)
+	src.Data.(*TestISO2).F52.Value[0] = 9
+	assert.Equal(t, byte(1), dstData.F52.Value[0])
+
+	err = src.CopyFields(map[int]int{2: 2, 7: 7}, dst)
+	assert.True(t, errors.Is(err, ErrFieldNotSet))
+}
+
+func TestBytesIdempotent(t *testing.T) {
+	fields := []struct {
+		field      Iso8583Type
+		encoder    int
+		lenEncoder int
+		length     int
+	}{
+		{NewNumeric("0643"), rBCD, ASCII, 3},
+		{NewNumeric("123"), BCD, ASCII, 6},
+		{NewNumeric("42"), ASCII, ASCII, 6},
+		{NewAlphanumeric("abc"), ASCII, ASCII, 6},
+		{NewBinary(make([]byte, 2, 8)), ASCII, ASCII, 4},
+		{NewLlvar([]byte("llvar")), ASCII, BCD, 20},
+		{NewLlnumeric("12345"), BCD, ASCII, 20},
+		{NewLlnumeric("12345"), rBCD, BCD, 20},
+		{NewLllvar([]byte("lllvar")), ASCII, ASCII, 20},
+		{NewLllnumeric("12345"), BCD, BCD, 20},
+	}
+
+	for _, f := range fields {
+		before := f.field.Copy()
+		first, err := f.field.Bytes(f.encoder, f.lenEncoder, f.length)
+		assert.Nil(t, err)
+		for i := 0; i < 2; i++ {
+			res, err := f.field.Bytes(f.encoder, f.lenEncoder, f.length)
+			assert.Nil(t, err)
+			assert.Equal(t, first, res)
+			assert.Equal(t, before, f.field)
+		}
+	}
+
+	// padding a binary value must not write into its spare capacity
+	backing := []byte{1, 2, 3, 4}
+	b := NewBinary(backing[:2])
+	res, err := b.Bytes(ASCII, ASCII, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{1, 2, 0, 0}, res)
+	assert.Equal(t, []byte{1, 2, 3, 4}, backing)
+
+	data := &TestISO2{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F19: NewNumeric("0643"),
+		F28: NewAlphanumeric("abcd"),
+		F52: NewBinary(make([]byte, 4, 16)),
+		F54: NewLlvar([]byte("llvar")),
+		F56: NewLllvar([]byte("lllvar")),
+		F60: NewLllnumeric("12345"),
+	}
+	iso := NewMessage("0100", data)
+	before := iso.Clone()
+	first, err := iso.Bytes()
+	assert.Nil(t, err)
+	for i := 0; i < 2; i++ {
+		res, err := iso.Bytes()
+		assert.Nil(t, err)
+		assert.Equal(t, first, res)
+		assert.Equal(t, before, iso)
+	}
+}
+
+func TestMessageGetFields(t *testing.T) {
+	data := &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F39: NewAlphanumeric(""),
+	}
+	iso := NewMessage("0100", data)
+
+	f, err := iso.GetField(3)
+	assert.Nil(t, err)
+	assert.Equal(t, data.F3, f)
+
+	_, err = iso.GetField(39)
+	assert.EqualError(t, err, "field 39: field not set")
+
+	fields, err := iso.GetFields(2, 3, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]Iso8583Type{2: data.F2, 3: data.F3, 4: data.F4}, fields)
+
+	_, err = iso.GetFields(2, 7, 3, 39)
+	assert.True(t, errors.Is(err, ErrFieldNotSet))
+	assert.EqualError(t, err, "fields 7, 39: field not set")
+
+	assert.Equal(t, map[int]Iso8583Type{2: data.F2, 4: data.F4}, iso.GetAvailableFields(2, 4, 7, 39))
+	assert.Empty(t, iso.GetAvailableFields(7, 11))
+
+	iso.Data = nil
+	_, err = iso.GetFields(2)
+	assert.EqualError(t, err, "Critical error:data must be a struct")
+	assert.Empty(t, iso.GetAvailableFields(2))
+}
+
+func TestMessageBitCount(t *testing.T) {
+	type data struct {
+		F2  *Llnumeric `field:"2" length:"19"`
+		F3  *Numeric   `field:"3" length:"6"`
+		F4  *Numeric   `field:"4" length:"12"`
+		F70 *Numeric   `field:"70" length:"3"`
+	}
+
+	iso := NewMessage("0100", &data{})
+	assert.Equal(t, 0, iso.BitCount())
+
+	iso.Data = &data{
+		F2: NewLlnumeric("4276555555555555"),
+		F3: NewNumeric("000000"),
+		F4: NewNumeric("000000077700"),
+	}
+	assert.Equal(t, 3, iso.BitCount())
+
+	iso.Data.(*data).F70 = NewNumeric("301")
+	iso.SecondBitmap = true
+	assert.Equal(t, 4, iso.BitCount())
+}
+
+func TestEnum(t *testing.T) {
+	codes := []string{"00", "05", "51", "91"}
+
+	_, err := NewEnum("99", codes)
+	assert.EqualError(t, err, `value is not allowed; type=Enum, value="99"`)
+
+	e, err := NewEnum("05", codes)
+	assert.Nil(t, err)
+	res, err := e.Bytes(ASCII, ASCII, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("05"), res)
+
+	e.Value = "XX"
+	_, err = e.Bytes(ASCII, ASCII, 2)
+	assert.EqualError(t, err, `value is not allowed; type=Enum, value="XX"`)
+
+	e.Value = "00"
+	read, err := e.Load([]byte("51"), ASCII, ASCII, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, read)
+	assert.Equal(t, "51", e.Value)
+
+	// out of spec wire values are rejected and leave the field untouched
+	_, err = e.Load([]byte("Z1"), ASCII, ASCII, 2)
+	assert.EqualError(t, err, `value is not allowed; type=Enum, value="Z1"`)
+	assert.Equal(t, "51", e.Value)
+
+	type data struct {
+		F39 *Enum `field:"39" length:"2"`
+	}
+	f39, _ := NewEnum("00", codes)
+	iso := NewMessage("0110", &data{F39: f39})
+	err = iso.Load([]byte{48, 49, 49, 48, 0, 0, 0, 0, 2, 0, 0, 0, 'Z', '1'})
+	assert.EqualError(t, err, `field 39: value is not allowed; type=Enum, value="Z1"`)
+
+	// an Enum without allowed values accepts anything
+	e = &Enum{}
+	_, err = e.Load([]byte("Z1"), ASCII, ASCII, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "Z1", e.Value)
+}
+
+func TestEnumAllowedTag(t *testing.T) {
+	type data struct {
+		F39 *Enum `field:"39" length:"2" allowed:"00,05,51,91"`
+	}
+	p := &Parser{}
+	assert.Nil(t, p.Register("0110", &data{}))
+
+	ok := []byte{48, 49, 49, 48, 0, 0, 0, 0, 2, 0, 0, 0, '0', '5'}
+	msg, err := p.Parse(ok)
+	assert.Nil(t, err)
+	assert.Equal(t, "05", msg.Data.(*data).F39.Value)
+
+	_, err = p.Parse([]byte{48, 49, 49, 48, 0, 0, 0, 0, 2, 0, 0, 0, 'Z', '1'})
+	assert.EqualError(t, err, `field 39: value is not allowed; type=Enum, value="Z1"`)
+
+	// the parsed field keeps the allowed values
+	msg.Data.(*data).F39.Value = "Z1"
+	_, err = msg.Data.(*data).F39.Bytes(ASCII, ASCII, 2)
+	assert.EqualError(t, err, `value is not allowed; type=Enum, value="Z1"`)
+
+	// the tag also applies to an Enum created without allowed values
+	_, err = NewMessage("0110", &data{F39: &Enum{Alphanumeric{Value: "Z1"}, nil}}).Bytes()
+	assert.EqualError(t, err, `value is not allowed; type=Enum, value="Z1"`)
+	f39 := &Enum{Alphanumeric{Value: "91"}, nil}
+	b, err := NewMessage("0110", &data{F39: f39}).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "91", string(b[12:]))
+	assert.Nil(t, f39.allowed)
+}
+
+func TestMessageExtent(t *testing.T) {
+	data := &TestISO2{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F54: NewLlvar([]byte("llvar")),
+		F64: NewBinary([]byte("0123456789abcdef0123456789abcdef")),
+	}
+	iso := NewMessage("0200", data)
+
+	_, _, err := Extents{}.Extent(0, 64)
+	assert.EqualError(t, err, "extents are not known")
+
+	res, ext, err := iso.BytesWithExtents()
+	assert.Nil(t, err)
+
+	start, end, err := ext.Extent(0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("0200"), res[start:end])
+
+	start, end, err = ext.Extent(3, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x00, 0x00}, res[start:end])
+
+	start, end, err = ext.Extent(64, 64)
+	assert.Nil(t, err)
+	assert.Equal(t, len(res), end)
+	assert.Equal(t, []byte("0123456789abcdef0123456789abcdef"), res[start:end])
+
+	macStart, macEnd, err := ext.Extent(0, 54)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, macStart)
+	assert.Equal(t, start, macEnd)
+
+	_, _, err = ext.Extent(0, 11)
+	assert.EqualError(t, err, "field 11: field not set")
+
+	_, _, err = ext.Extent(64, 2)
+	assert.EqualError(t, err, "field 2 is placed before field 64")
+
+	// a decode of the same bytes yields the same extents
+	parser := Parser{}
+	assert.Nil(t, parser.Register("0200", &TestISO2{}))
+	_, decoded, err := parser.ParseWithExtents(res)
+	assert.Nil(t, err)
+	for _, n := range []int{0, 1, 2, 3, 4, 54, 64} {
+		s1, e1, err := ext.Extent(0, n)
+		assert.Nil(t, err)
+		s2, e2, err := decoded.Extent(0, n)
+		assert.Nil(t, err)
+		assert.Equal(t, s1, s2)
+		assert.Equal(t, e1, e2)
+	}
+}
+
+func TestInvalidBCDLengthHead(t *testing.T) {
+	tests := []struct {
+		head     []byte
+		position int
+	}{
+		{[]byte{0xA1}, 0},
+		{[]byte{0x1A}, 1},
+		{[]byte{0xA0, 0x01}, 0},
+		{[]byte{0x0B, 0x01}, 1},
+		{[]byte{0x01, 0xC1}, 2},
+		{[]byte{0x01, 0x1F}, 3},
+		{[]byte{0x10, 0x05}, 0},
+	}
+	for _, tt := range tests {
+		raw := append(append([]byte(nil), tt.head...), []byte(strings.Repeat("1", 200))...)
+		expected := &ErrInvalidBCDLength{tt.head[tt.position/2], tt.position}
+
+		var fields []Iso8583Type
+		if len(tt.head) == 1 {
+			fields = []Iso8583Type{NewLlvar(nil), NewLlnumeric("")}
+		} else {
+			fields = []Iso8583Type{NewLllvar(nil), NewLllnumeric("")}
+		}
+		for _, f := range fields {
+			_, err := f.Load(raw, ASCII, BCD, -1)
+			var bcdErr *ErrInvalidBCDLength
+			assert.True(t, errors.As(err, &bcdErr), "%T % x", f, tt.head)
+			assert.Equal(t, expected, bcdErr)
+		}
+	}
+
+	_, err := NewLllvar(nil).Load([]byte{0x01, 0x1F}, ASCII, BCD, -1)
+	assert.EqualError(t, err, "parse length head failed: invalid BCD byte 0x1f at nibble 3")
+
+	// the four digits of a Llllvar head have no pad nibble
+	l := NewLlllvar(nil)
+	read, err := l.Load(append([]byte{0x10, 0x05}, bytes.Repeat([]byte("1"), 1005)...), ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1007, read)
+}
+
+func TestLllvarLoadLength(t *testing.T) {
+	raw := append([]byte("020"), bytes.Repeat([]byte("a"), 20)...)
+	_, err := NewLllvar(nil).Load(raw, ASCII, ASCII, 10)
+	assert.Equal(t, &ErrValueTooLong{"Lllvar", 10, 20}, err)
+
+	read, err := NewLllvar(nil).Load(raw, ASCII, ASCII, 20)
+	assert.Nil(t, err)
+	assert.Equal(t, 23, read)
+
+	_, err = NewLllvar(nil).Load(append([]byte("-01"), raw...), ASCII, ASCII, -1)
+	assert.Equal(t, &ErrBadRaw{"Lllvar", 2, 26}, err)
+}
+
+func TestLlvarBinaryLengthHead(t *testing.T) {
+	value := bytes.Repeat([]byte("a"), 300)
+
+	res, err := NewLlvar(value).Bytes(ASCII, BinaryLen2, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x01, 0x2C}, res[:2])
+	assert.Equal(t, value, res[2:])
+
+	l := NewLlvar(nil)
+	read, err := l.Load(res, ASCII, BinaryLen2, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 302, read)
+	assert.Equal(t, value, l.Value)
+
+	res, err = NewLlvar(value).Bytes(ASCII, BinaryLen2LE, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x2C, 0x01}, res[:2])
+
+	l = NewLlvar(nil)
+	read, err = l.Load(res, ASCII, BinaryLen2LE, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 302, read)
+	assert.Equal(t, value, l.Value)
+
+	_, err = l.Load([]byte{0x01}, ASCII, BinaryLen2, -1)
+	assert.EqualError(t, err, "bad raw data")
+	_, err = l.Load([]byte{0x01, 0x2C, 'a'}, ASCII, BinaryLen2, -1)
+	assert.EqualError(t, err, "bad raw data")
+
+	type data struct {
+		F2 *Llvar `field:"2" length:"999" encode:"binlen2le,ascii"`
+	}
+	iso := NewMessage("0100", &data{NewLlvar(value)})
+	res, err = iso.Bytes()
+	assert.Nil(t, err)
+	decoded := NewMessage("", &data{NewLlvar(nil)})
+	assert.Nil(t, decoded.Load(res))
+	assert.Equal(t, value, decoded.Data.(*data).F2.Value)
+}
+
+func TestBinaryComparison(t *testing.T) {
+	mac := NewBinary([]byte{0xDE, 0xAD, 0xBE, 0xEF})
+
+	assert.True(t, mac.Equal([]byte{0xDE, 0xAD, 0xBE, 0xEF}))
+	assert.True(t, mac.EqualConstantTime([]byte{0xDE, 0xAD, 0xBE, 0xEF}))
+
+	assert.False(t, mac.Equal([]byte{0xDE, 0xAD, 0xBE, 0xEE}))
+	assert.False(t, mac.EqualConstantTime([]byte{0xDE, 0xAD, 0xBE, 0xEE}))
+
+	assert.False(t, mac.Equal([]byte{0xDE, 0xAD, 0xBE}))
+	assert.False(t, mac.EqualConstantTime([]byte{0xDE, 0xAD, 0xBE}))
+	assert.False(t, mac.EqualConstantTime(nil))
+
+	assert.Equal(t, "DEADBEEF", mac.String())
+
+	clone := mac.Clone()
+	assert.Equal(t, mac, clone)
+	clone.Value[0] = 0
+	assert.Equal(t, byte(0xDE), mac.Value[0])
+}
+
+func TestMessageOnField(t *testing.T) {
+	input, err := NewMessage("0200", &TestISO2{
+		F3:  NewNumeric("000000"),
+		F2:  NewLlnumeric("4276555555555555"),
+		F52: NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+		F4:  NewNumeric("000000077700"),
+	}).Bytes()
+	assert.Nil(t, err)
+	buf := append([]byte(nil), input...)
+
+	var order []int
+	var pinBlock *Binary
+	var pinRaw []byte
+	msg := NewMessage("", &TestISO2{
+		F2: NewLlnumeric(""), F3: NewNumeric(""), F4: NewNumeric(""), F52: NewBinary(nil),
+	})
+	msg.OnField = func(n int, f Iso8583Type, raw []byte) {
+		order = append(order, n)
+		if n == 52 {
+			pinBlock = f.(*Binary)
+			pinRaw = raw
+		}
+	}
+	assert.Nil(t, msg.Load(buf))
+	assert.Equal(t, []int{2, 3, 4, 52}, order)
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, pinRaw)
+
+	// the callback does not alias the decode buffer
+	for i := range buf {
+		buf[i] = 0
+	}
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, pinBlock.Value)
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, pinRaw)
+
+	msg.OnField = func(n int, f Iso8583Type, raw []byte) {
+		if n == 4 {
+			panic("lookup failed")
+		}
+	}
+	assert.EqualError(t, msg.Load(input), "field 4: OnField panicked: lookup failed")
+
+	// the message can be loaded again after a failed callback
+	order = nil
+	msg.OnField = func(n int, f Iso8583Type, raw []byte) {
+		order = append(order, n)
+	}
+	assert.Nil(t, msg.Load(input))
+	assert.Equal(t, []int{2, 3, 4, 52}, order)
+	assert.Equal(t, "000000077700", msg.Data.(*TestISO2).F4.Value)
+}
+
+func TestMessageFindField(t *testing.T) {
+	iso := NewMessage("0200", &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F7:  NewNumeric("0701111844"),
+		F11: NewNumeric("123456"),
+		F12: NewNumeric("131844"),
+		F13: NewNumeric("0701"),
+		F37: NewAlphanumeric("123456"),
+		F41: NewAlphanumeric("00000321"),
+		F49: NewNumeric("643"),
+	})
+	is := func(value string) func(int, Iso8583Type) bool {
+		return func(n int, f Iso8583Type) bool {
+			switch v := f.(type) {
+			case *Numeric:
+				return v.Value == value
+			case *Alphanumeric:
+				return v.Value == value
+			}
+			return false
+		}
+	}
+
+	n, f, found := iso.FindField(is("123456"))
+	assert.True(t, found)
+	assert.Equal(t, 11, n)
+	assert.Equal(t, "123456", f.(*Numeric).Value)
+	assert.Equal(t, []int{11, 37}, iso.FindAllFields(is("123456")))
+
+	n, f, found = iso.FindField(is("00000321"))
+	assert.True(t, found)
+	assert.Equal(t, 41, n)
+
+	n, f, found = iso.FindField(is("999999"))
+	assert.False(t, found)
+	assert.Equal(t, 0, n)
+	assert.Nil(t, f)
+	assert.Empty(t, iso.FindAllFields(is("999999")))
+}
+
+func TestNumericAdd(t *testing.T) {
+	amount := NewNumeric("000000010000")
+	res, err := amount.Add(100)
+	assert.Nil(t, err)
+	assert.Equal(t, NewNumeric("000000010100"), res)
+	assert.Equal(t, "000000010000", amount.Value)
+
+	res, err = amount.Add(-10000)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000000000", res.Value)
+
+	_, err = amount.Add(-10001)
+	assert.Equal(t, ErrNegativeAmount, err)
+
+	_, err = NewNumeric("999").Add(1)
+	assert.Equal(t, ErrOverflow, err)
+	_, err = NewNumeric("9223372036854775807").Add(1)
+	assert.Equal(t, ErrOverflow, err)
+
+	_, err = NewNumeric("12A").Add(1)
+	assert.NotNil(t, err)
+}
+
+func TestLlvarPredicates(t *testing.T) {
+	l := NewLlvar([]byte("Hello, World ~"))
+	assert.True(t, l.IsASCIIPrintable())
+	assert.False(t, l.IsNumeric())
+	assert.False(t, l.IsHex())
+
+	l = NewLlvar([]byte("line\r\n"))
+	assert.False(t, l.IsASCIIPrintable())
+	l = NewLlvar([]byte{'a', 0x7F})
+	assert.False(t, l.IsASCIIPrintable())
+
+	l = NewLlvar([]byte("0123456789"))
+	assert.True(t, l.IsNumeric())
+	assert.True(t, l.IsHex())
+
+	l = NewLlvar([]byte("09afAF"))
+	assert.False(t, l.IsNumeric())
+	assert.True(t, l.IsHex())
+	l = NewLlvar([]byte("09afAG"))
+	assert.False(t, l.IsHex())
+
+	for _, l := range []*Llvar{NewLlvar(nil), NewLlvar([]byte{})} {
+		assert.True(t, l.IsASCIIPrintable())
+		assert.True(t, l.IsNumeric())
+		assert.True(t, l.IsHex())
+	}
+}
+
+func TestNumericPackedLength(t *testing.T) {
+	type data struct {
+		F3 *Numeric `field:"3" length:"6" encode:"bcd" packed:"4"`
+		F4 *Numeric `field:"4" length:"7" encode:"rbcd" packed:"4"`
+	}
+	msg := NewMessage("0200", &data{F3: NewNumeric("123456"), F4: NewNumeric("1234567")})
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x12, 0x34, 0x56, 0x01, 0x23, 0x45, 0x67}, b[12:])
+
+	parsed := NewMessage("", &data{F3: NewNumeric(""), F4: NewNumeric("")})
+	assert.Nil(t, parsed.Load(b))
+	assert.Equal(t, "123456", parsed.Data.(*data).F3.Value)
+	assert.Equal(t, "1234567", parsed.Data.(*data).F4.Value)
+
+	_, err = NewMessage("0200", &data{F3: NewNumeric("1234567")}).Bytes()
+	assert.EqualError(t, err, "length of value is longer than definition; type=Numeric, def_len=6, len=7")
+
+	// digits beyond the length in the packed bytes are not dropped
+	b[12] = 0x01
+	err = parsed.Load(b)
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.EqualError(t, err, "field 3: length of value is longer than definition; type=Numeric, def_len=6, len=7")
+
+	type short struct {
+		F3 *Numeric `field:"3" length:"6" encode:"bcd" packed:"2"`
+	}
+	_, err = NewMessage("0200", &short{F3: NewNumeric("123456")}).Bytes()
+	assert.EqualError(t, err, "packed length 2 is too short for length 6")
+
+	type ascii struct {
+		F3 *Numeric `field:"3" length:"6" packed:"4"`
+	}
+	_, err = NewMessage("0200", &ascii{F3: NewNumeric("123456")}).Bytes()
+	assert.EqualError(t, err, "packed length is only supported for bcd and rbcd Numeric fields")
+}
+
+func TestMessageSetFieldFromString(t *testing.T) {
+	type data struct {
+		F2  *Llnumeric    `field:"2" length:"19"`
+		F3  *Numeric      `field:"3" length:"6"`
+		F35 *Lllnumeric   `field:"35" length:"37"`
+		F39 *Enum         `field:"39" length:"2"`
+		F41 *Alphanumeric `field:"41" length:"8"`
+		F48 *Lllvar       `field:"48" length:"999"`
+		F52 *Binary       `field:"52" length:"8"`
+		F54 *Llvar        `field:"54" length:"99"`
+	}
+	d := &data{}
+	msg := NewMessage("0200", d)
+
+	assert.Nil(t, msg.SetFieldFromString(2, "4276555555555555"))
+	assert.Nil(t, msg.SetFieldFromString(3, "000000"))
+	assert.Nil(t, msg.SetFieldFromString(35, "4276555555555555=2512"))
+	assert.Nil(t, msg.SetFieldFromString(39, "00"))
+	assert.Nil(t, msg.SetFieldFromString(41, "TERM0001"))
+	assert.Nil(t, msg.SetFieldFromString(48, "private"))
+	assert.Nil(t, msg.SetFieldFromString(54, "llvar"))
+	assert.Nil(t, msg.SetFieldFromHex(52, "0102030405060708"))
+
+	assert.Equal(t, NewLlnumeric("4276555555555555"), d.F2)
+	assert.Equal(t, NewNumeric("000000"), d.F3)
+	assert.Equal(t, NewLllnumeric("4276555555555555=2512"), d.F35)
+	assert.Equal(t, "00", d.F39.Value)
+	assert.Equal(t, "TERM0001", d.F41.Value)
+	assert.Equal(t, NewLllvar([]byte("private")), d.F48)
+	assert.Equal(t, NewLlvar([]byte("llvar")), d.F54)
+	assert.Equal(t, NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}), d.F52)
+
+	_, err := msg.Bytes()
+	assert.Nil(t, err)
+
+	assert.EqualError(t, msg.SetFieldFromString(52, "0102"), "field 52: Binary field must be set with SetFieldFromHex")
+	assert.EqualError(t, msg.SetFieldFromHex(3, "0102"), "field 3: *iso8583.Numeric is not a Binary field")
+	assert.EqualError(t, msg.SetFieldFromHex(52, "zz"), "field 52: encoding/hex: invalid byte: U+007A 'z'")
+	assert.EqualError(t, msg.SetFieldFromString(4, "1"), "field 4 not defined")
+}
+
+func TestNilFields(t *testing.T) {
+	fields := map[string]Iso8583Type{
+		"Numeric":        (*Numeric)(nil),
+		"Alphanumeric":   (*Alphanumeric)(nil),
+		"Enum":           (*Enum)(nil),
+		"Binary":         (*Binary)(nil),
+		"Llvar":          (*Llvar)(nil),
+		"Llnumeric":      (*Llnumeric)(nil),
+		"Lllvar":         (*Lllvar)(nil),
+		"Lllnumeric":     (*Lllnumeric)(nil),
+		"Llalpha":        (*Llalpha)(nil),
+		"Llbinary":       (*Llbinary)(nil),
+		"Lllalpha":       (*Lllalpha)(nil),
+		"Lllbinary":      (*Lllbinary)(nil),
+		"Llllvar":        (*Llllvar)(nil),
+		"Llllnumeric":    (*Llllnumeric)(nil),
+		"TaggedLLLField": (*TaggedLLLField)(nil),
+	}
+	for name, f := range fields {
+		assert.True(t, f.IsEmpty(), name)
+
+		_, err := f.Bytes(ASCII, ASCII, 10)
+		var nilErr *ErrNilField
+		assert.True(t, errors.As(err, &nilErr), name)
+		assert.Equal(t, name, nilErr.Type)
+		assert.EqualError(t, err, "nil "+name+" field")
+
+		_, err = f.Load([]byte("0000000000"), ASCII, ASCII, 10)
+		assert.EqualError(t, err, "nil "+name+" field")
+	}
+
+	_, err := (*Numeric)(nil).Add(1)
+	assert.EqualError(t, err, "nil Numeric field")
+
+	var b *Binary
+	assert.True(t, b.Equal(nil))
+	assert.False(t, b.Equal([]byte{1}))
+	assert.True(t, b.EqualConstantTime(nil))
+	assert.Nil(t, b.Clone())
+	assert.Equal(t, "", b.String())
+
+	var l *Llvar
+	assert.True(t, l.IsASCIIPrintable())
+	assert.True(t, l.IsNumeric())
+	assert.True(t, l.IsHex())
+
+	var tagged *TaggedLLLField
+	_, ok := tagged.Get("BAT")
+	assert.False(t, ok)
+	assert.Nil(t, tagged.Tags())
+	assert.EqualError(t, tagged.Set("BAT", "01"), "nil TaggedLLLField field")
+
+	// nil fields are left out by the marshaller
+	type data struct {
+		F3  Iso8583Type `field:"3" length:"6"`
+		F4  *Numeric    `field:"4" length:"12"`
+		F11 *Numeric    `field:"11" length:"6"`
+	}
+	res, err := NewMessage("0200", &data{F3: (*Numeric)(nil), F11: NewNumeric("000001")}).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+string([]byte{0, 0x20, 0, 0, 0, 0, 0, 0})+"000001", string(res))
+}
+
+func TestFieldCopy(t *testing.T) {
+	enum, err := NewEnum("00", []string{"00", "05"})
+	assert.Nil(t, err)
+	tagged := NewTaggedLLLField()
+	assert.Nil(t, tagged.Set("BAT", "01"))
+
+	fields := []Iso8583Type{
+		NewNumeric("000100"),
+		NewAlphanumeric("TERM0001"),
+		enum,
+		NewBinary([]byte{1, 2, 3}),
+		NewLlvar([]byte("llvar")),
+		NewLlalpha("llalpha"),
+		NewLlbinary([]byte{0x00, 0xFF}),
+		NewLlnumeric("4276555555555555"),
+		NewLllvar([]byte("lllvar")),
+		NewLllalpha("lllalpha"),
+		NewLllbinary([]byte{0x80, 0x00}),
+		NewLllnumeric("123"),
+		NewLlllvar([]byte("llllvar")),
+		NewLlllnumeric("1234"),
+		tagged,
+	}
+	for _, f := range fields {
+		c := f.Copy()
+		assert.IsType(t, f, c)
+		assert.Equal(t, f, c)
+
+		before, err := f.Bytes(ASCII, ASCII, 16)
+		assert.Nil(t, err)
+		switch v := c.(type) {
+		case *Numeric:
+			v.Value = "999999"
+		case *Alphanumeric:
+			v.Value = "CHANGED"
+		case *Enum:
+			v.Value = "05"
+		case *Binary:
+			v.Value[0] = 9
+		case *Llvar:
+			v.Value[0] = 'L'
+		case *Llalpha:
+			v.Value = "changed"
+		case *Llbinary:
+			v.Value[0] = 9
+		case *Llnumeric:
+			v.Value = "4111111111111111"
+		case *Lllvar:
+			v.Value[0] = 'L'
+		case *Lllalpha:
+			v.Value = "changed"
+		case *Lllbinary:
+			v.Value[0] = 9
+		case *Lllnumeric:
+			v.Value = "321"
+		case *Llllvar:
+			v.Value[0] = 'L'
+		case *Llllnumeric:
+			v.Value = "4321"
+		case *TaggedLLLField:
+			assert.Nil(t, v.Set("BAT", "02"))
+		}
+		after, err := f.Bytes(ASCII, ASCII, 16)
+		assert.Nil(t, err)
+		assert.Equal(t, before, after, "%T", f)
+		assert.NotEqual(t, f, c, "%T", f)
+	}
+
+	assert.Equal(t, (*Numeric)(nil), (*Numeric)(nil).Copy())
+}
+
+func TestNumericWireBytes(t *testing.T) {
+	type consistent struct {
+		F2 *Numeric `field:"2" length:"19" encode:"rbcd" wirebytes:"10"`
+		F3 *Numeric `field:"3" length:"6" encode:"bcd" packed:"4" wirebytes:"4"`
+		F4 *Numeric `field:"4" length:"12" wirebytes:"12"`
+	}
+	parser := Parser{}
+	assert.Nil(t, parser.Register("0200", &consistent{}))
+	b, err := NewMessage("0200", &consistent{
+		F2: NewNumeric("4276555555555555555"),
+		F3: NewNumeric("000000"),
+		F4: NewNumeric("000000000100"),
+	}).Bytes()
+	assert.Nil(t, err)
+	msg, err := parser.Parse(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "4276555555555555555", msg.Data.(*consistent).F2.Value)
+
+	// the length was given in bytes as digits
+	type inconsistent struct {
+		F2 *Numeric `field:"2" length:"20" encode:"bcd" wirebytes:"20"`
+	}
+	err = parser.Register("0210", &inconsistent{})
+	assert.EqualError(t, err, "field 2: length 20 digits takes 10 bytes with bcd encoding, but wirebytes 20 holds 40 digits")
+	_, err = NewMessage("0210", &inconsistent{F2: NewNumeric("1")}).Bytes()
+	assert.EqualError(t, err, "field 2: length 20 digits takes 10 bytes with bcd encoding, but wirebytes 20 holds 40 digits")
+
+	type ascii struct {
+		F4 *Numeric `field:"4" length:"12" wirebytes:"6"`
+	}
+	_, err = NewMessage("0200", &ascii{F4: NewNumeric("100")}).Bytes()
+	assert.EqualError(t, err, "field 4: length 12 digits takes 12 bytes with ascii encoding, but wirebytes 6 holds 6 digits")
+
+	type notNumeric struct {
+		F41 *Alphanumeric `field:"41" length:"8" wirebytes:"8"`
+	}
+	err = parser.Register("0800", &notNumeric{})
+	assert.EqualError(t, err, "field 41: wirebytes is only supported for Numeric fields")
+}
+
+func TestNumericInt(t *testing.T) {
+	n := &Numeric{}
+	assert.Nil(t, n.SetInt(77700))
+	assert.Equal(t, "", n.Value)
+	assert.Equal(t, "77700", n.Digits())
+	b, err := n.Bytes(ASCII, ASCII, 12)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000077700", string(b))
+
+	loaded := &Numeric{}
+	_, err = loaded.Load(b, ASCII, ASCII, 12)
+	assert.Nil(t, err)
+	v, err := loaded.Int()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(77700), v)
+
+	assert.Equal(t, ErrNegativeAmount, n.SetInt(-1))
+	assert.Equal(t, "77700", n.Digits())
+
+	// 19 digits beyond int64 keep their string value
+	big := NewNumeric("9999999999999999999")
+	_, err = big.Int()
+	assert.NotNil(t, err)
+	b, err = big.Bytes(BCD, ASCII, 19)
+	assert.Nil(t, err)
+	_, err = big.Load(b, BCD, ASCII, 19)
+	assert.Nil(t, err)
+	assert.Equal(t, "9999999999999999999", big.Value)
+
+	max := NewNumeric("9223372036854775807")
+	v, err = max.Int()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9223372036854775807), v)
+}
+
+func TestNumericUint(t *testing.T) {
+	n := &Numeric{}
+	assert.Nil(t, n.SetUint(18446744073709551615))
+	assert.Equal(t, "18446744073709551615", n.Digits())
+	b, err := n.Bytes(BCD, ASCII, 20)
+	assert.Nil(t, err)
+
+	loaded := &Numeric{}
+	_, err = loaded.Load(b, BCD, ASCII, 20)
+	assert.Nil(t, err)
+	v, err := loaded.Uint()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(18446744073709551615), v)
+	// beyond int64, within uint64
+	_, err = loaded.Int()
+	assert.NotNil(t, err)
+
+	// 20 digits beyond uint64 keep their string value
+	big := NewNumeric("99999999999999999999")
+	_, err = big.Uint()
+	assert.NotNil(t, err)
+	b, err = big.Bytes(BCD, ASCII, 20)
+	assert.Nil(t, err)
+	_, err = big.Load(b, BCD, ASCII, 20)
+	assert.Nil(t, err)
+	assert.Equal(t, "99999999999999999999", big.Value)
+
+	var nilNumeric *Numeric
+	assert.Equal(t, &ErrNilField{"Numeric"}, nilNumeric.SetUint(1))
+	_, err = nilNumeric.Uint()
+	assert.Equal(t, &ErrNilField{"Numeric"}, err)
+}
+
+func TestNumericIntEncoding(t *testing.T) {
+	for _, length := range []int{5, 6, 12} {
+		for _, enc := range []int{ASCII, BCD, rBCD, EBCDIC} {
+			n := &Numeric{}
+			assert.Nil(t, n.SetInt(77700))
+			got, err := n.Bytes(enc, ASCII, length)
+			assert.Nil(t, err)
+			want, err := NewNumeric("77700").Bytes(enc, ASCII, length)
+			assert.Nil(t, err)
+			assert.Equal(t, want, got, "encoder %d, length %d", enc, length)
+
+			appended, err := n.AppendBytes([]byte{0xAA}, enc, length)
+			assert.Nil(t, err)
+			assert.Equal(t, append([]byte{0xAA}, want...), appended)
+		}
+	}
+
+	n := &Numeric{}
+	assert.Nil(t, n.SetInt(123456))
+	_, err := n.Bytes(BCD, ASCII, 5)
+	assert.Equal(t, &ErrValueTooLong{"Numeric", 5, 6}, err)
+	_, err = n.AppendBytes(nil, ASCII, -1)
+	assert.Equal(t, &ErrMissingLength{}, err)
+	assert.False(t, n.IsEmpty())
+	sum, err := n.Add(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "123457", sum.Digits())
+
+	// setting Value replaces the integer, keeping its leading zeros
+	n.Value = "000042"
+	assert.Equal(t, "000042", n.Digits())
+	v, err := n.Int()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), v)
+	assert.Nil(t, n.SetInt(7))
+	_, err = n.Load([]byte("000000"), ASCII, ASCII, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000", n.Digits())
+}
+
+func BenchmarkNumericSetIntBytes(b *testing.B) {
+	n := &Numeric{}
+	buf := make([]byte, 0, 16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := n.SetInt(int64(i % 1000000000000)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := n.AppendBytes(buf[:0], BCD, 12); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNumericLoadInt(b *testing.B) {
+	raw, err := NewNumeric("77700").Bytes(BCD, ASCII, 12)
+	if err != nil {
+		b.Fatal(err)
+	}
+	n := &Numeric{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := n.Load(raw, BCD, ASCII, 12); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := n.Int(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNewNumericFixed(t *testing.T) {
+	n, err := NewNumericFixed("42", 6)
+	assert.Nil(t, err)
+	assert.Equal(t, "000042", n.Value)
+	b, err := n.Bytes(ASCII, ASCII, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, n.Value, string(b))
+
+	n, err = NewNumericFixed("", 4)
+	assert.Nil(t, err)
+	assert.Equal(t, "0000", n.Value)
+	// a zero amount is a value, so the field is sent
+	assert.False(t, n.IsEmpty())
+
+	n, err = NewNumericFixed("123456", 6)
+	assert.Nil(t, err)
+	assert.Equal(t, "123456", n.Value)
+
+	_, err = NewNumericFixed("1234567", 6)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Numeric, def_len=6, len=7")
+	_, err = NewNumericFixed("12a4", 6)
+	assert.EqualError(t, err, `invalid digit 'a' at 2 in Numeric value`)
+}
+
+func TestEvenDigits(t *testing.T) {
+	type data struct {
+		F3  *Numeric    `field:"3" length:"6" encode:"bcd" evendigits:"true"`
+		F32 *Llnumeric  `field:"32" length:"11" encode:"bcd,rbcd" evendigits:"true"`
+		F48 *Lllnumeric `field:"48" length:"999" encode:"bcd,bcd" evendigits:"true"`
+		F49 *Numeric    `field:"49" length:"3" encode:"bcd"`
+		F50 *Numeric    `field:"50" length:"3" encode:"bcd" evendigits:"true"`
+	}
+
+	// the unflagged field 49 is padded as before
+	_, err := NewMessage("0200", &data{
+		F3:  NewNumeric("123456"),
+		F32: NewLlnumeric("1234"),
+		F48: NewLllnumeric("12"),
+		F49: NewNumeric("764"),
+	}).Bytes()
+	assert.Nil(t, err)
+
+	// a fixed Numeric is zero padded to its length, which is what counts
+	b, err := NewMessage("0200", &data{F3: NewNumeric("12345")}).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x01, 0x23, 0x45}, b[12:])
+	_, err = NewMessage("0200", &data{F50: NewNumeric("12")}).Bytes()
+	var odd *ErrOddDigitCount
+	assert.True(t, errors.As(err, &odd))
+	assert.Equal(t, ErrOddDigitCount{Field: 50, Len: 3}, *odd)
+	assert.EqualError(t, err, "field 50: odd number of digits 3, even required")
+
+	_, err = NewMessage("0200", &data{F32: NewLlnumeric("123")}).Bytes()
+	assert.EqualError(t, err, "field 32: odd number of digits 3, even required")
+	_, err = NewMessage("0200", &data{F48: NewLllnumeric("1")}).Bytes()
+	assert.EqualError(t, err, "field 48: odd number of digits 1, even required")
+}
+
+func TestLlbinary(t *testing.T) {
+	value := []byte{0x00, 0x9F, 0x26, 0x08, 0xFF, 0x00, 0x80, 0xC3}
+	for _, c := range []struct {
+		lenEncoder int
+		head       []byte
+	}{
+		{ASCII, []byte("08")},
+		{BCD, []byte{0x08}},
+		{rBCD, []byte{0x08}},
+	} {
+		b, err := NewLlbinary(value).Bytes(ASCII, c.lenEncoder, 99)
+		assert.Nil(t, err)
+		assert.Equal(t, append(c.head, value...), b)
+
+		loaded := &Llbinary{}
+		read, err := loaded.Load(append(b, 0x01), ASCII, c.lenEncoder, 99)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), read)
+		assert.Equal(t, value, loaded.Value)
+	}
+
+	_, err := NewLlbinary(make([]byte, 100)).Bytes(ASCII, BCD, -1)
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_HEAD)
+	_, err = NewLlbinary(value).Bytes(ASCII, ASCII, 4)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Llbinary, def_len=4, len=8")
+	_, err = (&Llbinary{}).Load([]byte{0x08, 0x00}, ASCII, BCD, 99)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+
+	type data struct {
+		F55 *Llbinary `field:"55" length:"99" encode:"bcd,ascii"`
+	}
+	msg := NewMessage("0200", &data{})
+	assert.Nil(t, msg.SetFieldFromHex(55, "009F2608FF0080C3"))
+	assert.EqualError(t, msg.SetFieldFromString(55, "x"), "field 55: Llbinary field must be set with SetFieldFromHex")
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	parsed := NewMessage("", &data{F55: &Llbinary{}})
+	assert.Nil(t, parsed.Load(b))
+	assert.Equal(t, value, parsed.Data.(*data).F55.Value)
+	s, err := parsed.GetString(55)
+	assert.Nil(t, err)
+	assert.Equal(t, "009F2608FF0080C3", s)
+}
+
+func TestLllbinary(t *testing.T) {
+	value := make([]byte, 999)
+	for i := range value {
+		value[i] = byte(0x80 + i)
+	}
+	for _, c := range []struct {
+		lenEncoder int
+		head       []byte
+	}{
+		{ASCII, []byte("999")},
+		{BCD, []byte{0x09, 0x99}},
+		{rBCD, []byte{0x09, 0x99}},
+	} {
+		b, err := NewLllbinary(value).Bytes(ASCII, c.lenEncoder, 999)
+		assert.Nil(t, err)
+		assert.Equal(t, append(c.head, value...), b)
+
+		loaded := &Lllbinary{}
+		read, err := loaded.Load(append(b, 0x01), ASCII, c.lenEncoder, 999)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), read)
+		assert.Equal(t, value, loaded.Value)
+	}
+
+	// empty payload
+	b, err := NewLllbinary(nil).Bytes(ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("000"), b)
+	loaded := &Lllbinary{}
+	read, err := loaded.Load([]byte("000"), ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, read)
+	assert.True(t, loaded.IsEmpty())
+
+	_, err = NewLllbinary(make([]byte, 1000)).Bytes(ASCII, BCD, -1)
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_HEAD)
+	_, err = (&Lllbinary{}).Load([]byte{0x00, 0x08, 0x00}, ASCII, BCD, 999)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+	_, err = (&Lllbinary{}).Load(append([]byte("010"), value...), ASCII, ASCII, 8)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Lllbinary, def_len=8, len=10")
+	_, err = (&Lllbinary{}).Load(append([]byte{0x10, 0x05}, value...), ASCII, BCD, -1)
+	assert.EqualError(t, err, "parse length head failed: invalid BCD byte 0x10 at nibble 0")
+
+	type data struct {
+		F120 *Lllbinary `field:"120" length:"999" encode:"bcd,ascii"`
+	}
+	msg := NewMessage("0200", &data{})
+	msg.SecondaryBitmap = SecondaryBitmapAuto
+	assert.Nil(t, msg.SetFieldFromHex(120, "00FF80"))
+	assert.EqualError(t, msg.SetFieldFromString(120, "x"), "field 120: Lllbinary field must be set with SetFieldFromHex")
+	b, err = msg.Bytes()
+	assert.Nil(t, err)
+	parsed := NewMessage("", &data{F120: &Lllbinary{}})
+	assert.Nil(t, parsed.Load(b))
+	assert.Equal(t, []byte{0x00, 0xFF, 0x80}, parsed.Data.(*data).F120.Value)
+}
+
+func TestLlalpha(t *testing.T) {
+	value := strings.Repeat("ABCDEFGHI ", 9) + "ABCDEFGHI"
+	for _, c := range []struct {
+		lenEncoder int
+		head       []byte
+	}{
+		{ASCII, []byte("99")},
+		{BCD, []byte{0x99}},
+		{rBCD, []byte{0x99}},
+	} {
+		b, err := NewLlalpha(value).Bytes(ASCII, c.lenEncoder, 99)
+		assert.Nil(t, err)
+		assert.Equal(t, append(c.head, value...), b)
+
+		loaded := &Llalpha{}
+		read, err := loaded.Load(append(b, '1'), ASCII, c.lenEncoder, 99)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), read)
+		assert.Equal(t, value, loaded.Value)
+	}
+
+	// zero length value
+	b, err := NewLlalpha("").Bytes(ASCII, BCD, 99)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00}, b)
+	loaded := &Llalpha{}
+	read, err := loaded.Load(b, ASCII, BCD, 99)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, read)
+	assert.True(t, loaded.IsEmpty())
+
+	// trailing spaces are trimmed
+	read, err = loaded.Load([]byte("05ACME  "), ASCII, ASCII, 99)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, read)
+	assert.Equal(t, "ACME", loaded.Value)
+
+	_, err = NewLlalpha(value+"X").Bytes(ASCII, ASCII, -1)
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_HEAD)
+	_, err = NewLlalpha("ACME").Bytes(ASCII, ASCII, 3)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Llalpha, def_len=3, len=4")
+	_, err = NewLlalpha("ACME").Bytes(BCD, ASCII, 99)
+	assert.EqualError(t, err, ERR_INVALID_ENCODER)
+	_, err = loaded.Load([]byte("05ACM"), ASCII, ASCII, 99)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+}
+
+func TestLlllvar(t *testing.T) {
+	value := []byte(strings.Repeat("0123456789", 1000))
+	for _, c := range []struct {
+		lenEncoder int
+		head       []byte
+	}{
+		{ASCII, []byte("9999")},
+		{BCD, []byte{0x99, 0x99}},
+		{rBCD, []byte{0x99, 0x99}},
+	} {
+		b, err := NewLlllvar(value[:9999]).Bytes(ASCII, c.lenEncoder, 9999)
+		assert.Nil(t, err)
+		assert.Equal(t, append(c.head, value[:9999]...), b)
+
+		loaded := &Llllvar{}
+		read, err := loaded.Load(append(b, '1'), ASCII, c.lenEncoder, 9999)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), read)
+		assert.Equal(t, value[:9999], loaded.Value)
+	}
+
+	b, err := NewLlllvar([]byte("AB")).Bytes(ASCII, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x02, 'A', 'B'}, b)
+	b, err = NewLlllvar([]byte("AB")).Bytes(ASCII, ASCII, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("0002AB"), b)
+
+	_, err = NewLlllvar(value).Bytes(ASCII, ASCII, -1)
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_HEAD)
+	_, err = NewLlllvar([]byte("ABC")).Bytes(ASCII, ASCII, 2)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Llllvar, def_len=2, len=3")
+
+	loaded := &Llllvar{}
+	// the length head is checked against the remaining bytes
+	_, err = loaded.Load([]byte("0003AB"), ASCII, ASCII, -1)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+	_, err = loaded.Load([]byte{0x00}, ASCII, BCD, -1)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+	// and against the length of the field
+	_, err = loaded.Load([]byte("0003ABC"), ASCII, ASCII, 2)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Llllvar, def_len=2, len=3")
+	_, err = loaded.Load([]byte("00x3ABC"), ASCII, ASCII, 2)
+	assert.EqualError(t, err, ERR_PARSE_LENGTH_FAILED+": 00x3")
+
+	type data struct {
+		F127 *Llllvar `field:"127" length:"9999" encode:"bcd,ascii"`
+	}
+	msg := NewMessage("0200", &data{F127: NewLlllvar([]byte("private data"))})
+	msg.SecondaryBitmap = SecondaryBitmapAuto
+	b, err = msg.Bytes()
+	assert.Nil(t, err)
+	parsed := NewMessage("", &data{F127: &Llllvar{}})
+	assert.Nil(t, parsed.Load(b))
+	assert.Equal(t, []byte("private data"), parsed.Data.(*data).F127.Value)
+}
+
+func TestLllalpha(t *testing.T) {
+	value := strings.Repeat("ABCDEFGHI ", 99) + "ABCDEFGHI"
+	for _, c := range []struct {
+		lenEncoder int
+		head       []byte
+	}{
+		{ASCII, []byte("999")},
+		{BCD, []byte{0x09, 0x99}},
+		{rBCD, []byte{0x09, 0x99}},
+	} {
+		b, err := NewLllalpha(value).Bytes(ASCII, c.lenEncoder, 999)
+		assert.Nil(t, err)
+		assert.Equal(t, append(c.head, value...), b)
+
+		loaded := &Lllalpha{}
+		read, err := loaded.Load(append(b, '1'), ASCII, c.lenEncoder, 999)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), read)
+		assert.Equal(t, value, loaded.Value)
+	}
+
+	// zero length value
+	b, err := NewLllalpha("").Bytes(ASCII, BCD, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x00}, b)
+	loaded := &Lllalpha{}
+	read, err := loaded.Load(b, ASCII, BCD, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, read)
+	assert.True(t, loaded.IsEmpty())
+
+	// trailing spaces are trimmed
+	read, err = loaded.Load([]byte("006ACME  "), ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, 9, read)
+	assert.Equal(t, "ACME", loaded.Value)
+
+	_, err = NewLllalpha(value+"X").Bytes(ASCII, ASCII, -1)
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_HEAD)
+	_, err = NewLllalpha("ACME").Bytes(ASCII, ASCII, 3)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Lllalpha, def_len=3, len=4")
+	_, err = NewLllalpha("ACME").Bytes(BCD, ASCII, 999)
+	assert.EqualError(t, err, ERR_INVALID_ENCODER)
+	_, err = loaded.Load([]byte("005ACM"), ASCII, ASCII, 999)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+	_, err = loaded.Load([]byte{0x00}, ASCII, BCD, 999)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+
+	// heads over the length tag or 999 are rejected before the value is read
+	_, err = loaded.Load([]byte("006ACME  "), ASCII, ASCII, 4)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Lllalpha, def_len=4, len=6")
+	_, err = loaded.Load(append([]byte{0x10, 0x05}, value...), ASCII, BCD, -1)
+	assert.EqualError(t, err, "parse length head failed: invalid BCD byte 0x10 at nibble 0")
+}
+
+func TestLlllnumeric(t *testing.T) {
+	digits := strings.Repeat("0123456789", 16)
+	for _, value := range []string{digits[:150], digits[:151]} {
+		for _, enc := range []int{ASCII, BCD, rBCD, EBCDIC} {
+			for _, lenEnc := range []int{ASCII, BCD, rBCD, EBCDIC} {
+				b, err := NewLlllnumeric(value).Bytes(enc, lenEnc, 9999)
+				assert.Nil(t, err)
+
+				loaded := &Llllnumeric{}
+				read, err := loaded.Load(append(b, '1'), enc, lenEnc, 9999)
+				assert.Nil(t, err, "%d %d", enc, lenEnc)
+				assert.Equal(t, len(b), read, "%d %d", enc, lenEnc)
+				assert.Equal(t, value, loaded.Value, "%d %d", enc, lenEnc)
+			}
+		}
+	}
+
+	// odd lengths are padded at the end for bcd and at the start for rbcd
+	b, err := NewLlllnumeric("123").Bytes(BCD, BCD, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x03, 0x12, 0x30}, b)
+	b, err = NewLlllnumeric("123").Bytes(rBCD, ASCII, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{'0', '0', '0', '3', 0x01, 0x23}, b)
+
+	_, err = NewLlllnumeric(strings.Repeat("1", 10000)).Bytes(ASCII, ASCII, -1)
+	assert.EqualError(t, err, ERR_INVALID_LENGTH_HEAD)
+	_, err = NewLlllnumeric("123").Bytes(ASCII, ASCII, 2)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Llllnumeric, def_len=2, len=3")
+	loaded := &Llllnumeric{}
+	_, err = loaded.Load([]byte{0x00, 0x03, 0x12}, BCD, BCD, -1)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+	_, err = loaded.Load([]byte("003"), ASCII, ASCII, -1)
+	assert.EqualError(t, err, ERR_BAD_RAW)
+	_, err = loaded.Load([]byte("0003123"), ASCII, ASCII, 2)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Llllnumeric, def_len=2, len=3")
+}
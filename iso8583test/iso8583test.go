@@ -0,0 +1,104 @@
+// Package iso8583test provides assertion helpers for tests of ISO 8583
+// messages, such as certification scripts.
+//
+// Expected values may contain wildcards, one per character:
+//
+//	x or X  any character
+//	9       any digit
+//	A       any uppercase letter
+//	?       any character
+//
+// A backslash makes the next character literal, so `\9` matches only "9".
+package iso8583test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/EknarongAphiphutthikul/iso8583"
+)
+
+// Match checks value against pattern. The error lists every mismatched
+// position.
+func Match(pattern, value string) error {
+	var mismatches []string
+	i := 0
+	for p := 0; p < len(pattern); p++ {
+		c := pattern[p]
+		literal := c == '\\' && p+1 < len(pattern)
+		if literal {
+			p++
+			c = pattern[p]
+		}
+		if i >= len(value) {
+			i++
+			continue
+		}
+		v := value[i]
+		var want string
+		switch {
+		case literal:
+			if v != c {
+				want = fmt.Sprintf("%q", c)
+			}
+		case c == 'x' || c == 'X' || c == '?':
+		case c == '9':
+			if v < '0' || v > '9' {
+				want = "a digit"
+			}
+		case c == 'A':
+			if v < 'A' || v > 'Z' {
+				want = "an uppercase letter"
+			}
+		default:
+			if v != c {
+				want = fmt.Sprintf("%q", c)
+			}
+		}
+		if want != "" {
+			mismatches = append(mismatches, fmt.Sprintf("position %d is %q, expected %s", i, v, want))
+		}
+		i++
+	}
+	if i != len(value) {
+		mismatches = append(mismatches, fmt.Sprintf("length is %d, expected %d", len(value), i))
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%q does not match %q: %s", value, pattern, strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// AssertField checks field n of msg against pattern and reports a failure
+// to t
+func AssertField(t testing.TB, msg *iso8583.Message, n int, pattern string) bool {
+	t.Helper()
+	value, err := msg.GetString(n)
+	if err != nil {
+		t.Errorf("%s", err)
+		return false
+	}
+	if err := Match(pattern, value); err != nil {
+		t.Errorf("field %d: %s", n, err)
+		return false
+	}
+	return true
+}
+
+// AssertFields checks the fields of msg against the patterns of expected,
+// by field number, and reports every failure to t
+func AssertFields(t testing.TB, msg *iso8583.Message, expected map[int]string) bool {
+	t.Helper()
+	ns := make([]int, 0, len(expected))
+	for n := range expected {
+		ns = append(ns, n)
+	}
+	sort.Ints(ns)
+	ok := true
+	for _, n := range ns {
+		ok = AssertField(t, msg, n, expected[n]) && ok
+	}
+	return ok
+}
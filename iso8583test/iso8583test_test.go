@@ -0,0 +1,76 @@
+package iso8583test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/EknarongAphiphutthikul/iso8583"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern, value string
+		err            string
+	}{
+		{"xxxx", "a1 Z", ""},
+		{"XXXX", "a1 Z", ""},
+		{"????", "a1 Z", ""},
+		{"9999", "0123", ""},
+		{"99", "1a", `"1a" does not match "99": position 1 is 'a', expected a digit`},
+		{"AA", "Zz", `"Zz" does not match "AA": position 1 is 'z', expected an uppercase letter`},
+		{"A?????", "B12345", ""},
+		{"00", "05", `"05" does not match "00": position 1 is '5', expected '0'`},
+		{"00", "00", ""},
+		{`TERM\9999`, "TERM9123", ""},
+		{`TERM\9999`, "TERM8123", `"TERM8123" does not match "TERM\\9999": position 4 is '8', expected '9'`},
+		{"xx", "abc", `"abc" does not match "xx": length is 3, expected 2`},
+		{"A9x", "a", `"a" does not match "A9x": position 0 is 'a', expected an uppercase letter; length is 1, expected 3`},
+	} {
+		err := Match(c.pattern, c.value)
+		if c.err == "" {
+			assert.NoError(t, err, c.pattern)
+		} else {
+			assert.EqualError(t, err, c.err, c.pattern)
+		}
+	}
+}
+
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertFields(t *testing.T) {
+	type data struct {
+		F11 *iso8583.Numeric      `field:"11" length:"6" encode:"ascii"`
+		F37 *iso8583.Alphanumeric `field:"37" length:"12"`
+		F38 *iso8583.Alphanumeric `field:"38" length:"6"`
+		F39 *iso8583.Alphanumeric `field:"39" length:"2"`
+	}
+	msg := iso8583.NewMessage("0210", &data{
+		F11: iso8583.NewNumeric("000001"),
+		F37: iso8583.NewAlphanumeric("123456789012"),
+		F38: iso8583.NewAlphanumeric("a12345"),
+		F39: iso8583.NewAlphanumeric("00"),
+	})
+
+	r := &recorder{}
+	assert.True(t, AssertField(r, msg, 37, "xxxxxxxxxxxx"))
+	assert.False(t, AssertFields(r, msg, map[int]string{
+		11: "999999",
+		38: "A?????",
+		39: "00",
+		41: "xxxxxxxx",
+	}))
+	assert.Equal(t, []string{
+		`field 38: "a12345" does not match "A?????": position 0 is 'a', expected an uppercase letter`,
+		"field 41: field not set",
+	}, r.errors)
+}
@@ -0,0 +1,49 @@
+package iso8583
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewMessageFromKV creates a message from the key-value text format used by
+// test fixtures. Each line is either "mti=0200" or "field:N=value"; empty
+// lines and lines starting with "#" are skipped. Values are set on the
+// fields of data, which must be a pointer to a tagged struct. Binary field
+// values are given in hex.
+func NewMessageFromKV(kvStr string, data interface{}) (*Message, error) {
+	msg := NewMessage("", data)
+	scanner := bufio.NewScanner(strings.NewReader(kvStr))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		eq := strings.Index(text, "=")
+		if eq == -1 {
+			return nil, fmt.Errorf("line %d: missing '='", line)
+		}
+		key, value := text[:eq], text[eq+1:]
+		switch {
+		case key == "mti":
+			msg.Mti = value
+		case strings.HasPrefix(key, "field:"):
+			n, err := strconv.Atoi(key[len("field:"):])
+			if err != nil || n < 2 || n > 128 {
+				return nil, fmt.Errorf("line %d: invalid field number %q", line, key[len("field:"):])
+			}
+			if err := setFieldString(data, n, value); err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unrecognized key %q", line, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
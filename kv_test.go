@@ -0,0 +1,65 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessageFromKV(t *testing.T) {
+	kv := `# authorization fixture
+mti=0100
+field:2=4276555555555555
+field:3=000000
+field:4=000000077700
+
+field:7=0701111844
+field:11=000123
+field:19=643
+field:37=987654321001
+field:41=00000321
+field:52=0102030405060708
+field:120=Another test text
+`
+	data := &TestISO{}
+	msg, err := NewMessageFromKV(kv, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "0100", msg.Mti)
+	assert.Equal(t, "4276555555555555", data.F2.Value)
+	assert.Equal(t, "000000", data.F3.Value)
+	assert.Equal(t, "000000077700", data.F4.Value)
+	assert.Equal(t, "0701111844", data.F7.Value)
+	assert.Equal(t, "000123", data.F11.Value)
+	assert.Equal(t, "643", data.F19.Value)
+	assert.Equal(t, "987654321001", data.F37.Value)
+	assert.Equal(t, "00000321", data.F41.Value)
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, data.F52.Value)
+	assert.Equal(t, "Another test text", data.F120.Value)
+	assert.Nil(t, data.F12)
+
+	_, err = msg.Bytes()
+	assert.Nil(t, err)
+}
+
+func TestNewMessageFromKVErrors(t *testing.T) {
+	_, err := NewMessageFromKV("field:x=1", &TestISO{})
+	assert.EqualError(t, err, `line 1: invalid field number "x"`)
+
+	_, err = NewMessageFromKV("mti=0100\nfield:200=1", &TestISO{})
+	assert.EqualError(t, err, `line 2: invalid field number "200"`)
+
+	_, err = NewMessageFromKV("field:5=1", &TestISO{})
+	assert.EqualError(t, err, "line 1: field 5 not defined")
+
+	_, err = NewMessageFromKV("pan=1", &TestISO{})
+	assert.EqualError(t, err, `line 1: unrecognized key "pan"`)
+
+	_, err = NewMessageFromKV("field:2", &TestISO{})
+	assert.EqualError(t, err, "line 1: missing '='")
+
+	_, err = NewMessageFromKV("field:52=zz", &TestISO{})
+	assert.EqualError(t, err, "line 1: field 52: encoding/hex: invalid byte: U+007A 'z'")
+
+	_, err = NewMessageFromKV("field:2=1", TestISO{})
+	assert.EqualError(t, err, "line 1: data must be a pointer to struct")
+}
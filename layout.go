@@ -0,0 +1,98 @@
+package iso8583
+
+import (
+	"errors"
+)
+
+func init() {
+	registerFeature("message_encoder")
+}
+
+// MessageEncoder places the encoded MTI, bitmap and fields of a message,
+// for proprietary protocols which do not send them in this order
+type MessageEncoder interface {
+	// Encode joins the encoded parts of a message
+	Encode(mti, bitmap, fields []byte) ([]byte, error)
+
+	// Decode splits raw into its parts. The MTI takes mtiLen bytes; the
+	// size of the bitmap is given by its first bit, see BitmapLen.
+	Decode(raw []byte, mtiLen int) (mti, bitmap, fields []byte, err error)
+}
+
+// DefaultEncoder is the standard layout: MTI, bitmap, fields
+type DefaultEncoder struct{}
+
+// Encode implements MessageEncoder
+func (DefaultEncoder) Encode(mti, bitmap, fields []byte) ([]byte, error) {
+	ret := make([]byte, 0, len(mti)+len(bitmap)+len(fields))
+	ret = append(ret, mti...)
+	ret = append(ret, bitmap...)
+	return append(ret, fields...), nil
+}
+
+// Decode implements MessageEncoder
+func (DefaultEncoder) Decode(raw []byte, mtiLen int) (mti, bitmap, fields []byte, err error) {
+	if len(raw) < mtiLen {
+		return nil, nil, nil, errors.New("bad MTI raw data")
+	}
+	n, err := BitmapLen(raw[mtiLen:])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return raw[:mtiLen], raw[mtiLen : mtiLen+n], raw[mtiLen+n:], nil
+}
+
+// BitmapLen returns the size of the bitmap starting raw: 16 bytes when
+// the first bit flags a secondary bitmap, 8 otherwise
+func BitmapLen(raw []byte) (int, error) {
+	if len(raw) < 8 {
+		return 0, errors.New("bitmap too short")
+	}
+	if raw[0]&0x80 == 0 {
+		return 8, nil
+	}
+	if len(raw) < 16 {
+		return 0, errors.New("secondary bitmap too short")
+	}
+	return 16, nil
+}
+
+// WithEncoder sets the encoder placing the parts of the message and
+// returns the message. The extents of such a message are not known, since
+// fields are placed by enc.
+func (m *Message) WithEncoder(enc MessageEncoder) *Message {
+	m.Encoder = enc
+	return m
+}
+
+// encodeWithEncoder encodes the message in the standard layout and has the
+// Encoder of the message place its parts. The extents returned are those of
+// the standard layout.
+func (m *Message) encodeWithEncoder() ([]byte, map[int]extent, error) {
+	c := *m
+	c.Encoder = nil
+	b, extents, err := c.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	mti, bitmap := extents[0], extents[1]
+	ret, err := m.Encoder.Encode(b[mti.start:mti.end], b[bitmap.start:bitmap.end], b[bitmap.end:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return ret, extents, nil
+}
+
+// standardLayout has enc split raw and joins the parts in the standard
+// layout
+func standardLayout(enc MessageEncoder, raw []byte, mtiEncode int) ([]byte, error) {
+	mtiLen := 4
+	if mtiEncode == BCD {
+		mtiLen = 2
+	}
+	mti, bitmap, fields, err := enc.Decode(raw, mtiLen)
+	if err != nil {
+		return nil, err
+	}
+	return DefaultEncoder{}.Encode(mti, bitmap, fields)
+}
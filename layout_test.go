@@ -0,0 +1,60 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bitmapFirst sends the bitmap before the MTI
+type bitmapFirst struct{}
+
+func (bitmapFirst) Encode(mti, bitmap, fields []byte) ([]byte, error) {
+	return DefaultEncoder{}.Encode(bitmap, mti, fields)
+}
+
+func (bitmapFirst) Decode(raw []byte, mtiLen int) (mti, bitmap, fields []byte, err error) {
+	n, err := BitmapLen(raw)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(raw) < n+mtiLen {
+		return nil, nil, nil, errors.New("bad MTI raw data")
+	}
+	return raw[n : n+mtiLen], raw[:n], raw[n+mtiLen:], nil
+}
+
+func TestMessageEncoder(t *testing.T) {
+	data := &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F11: NewNumeric("000001"),
+	}
+	std, err := NewMessage("0200", data).Bytes()
+	assert.Nil(t, err)
+	def, err := NewMessage("0200", data).WithEncoder(DefaultEncoder{}).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, std, def)
+
+	msg := NewMessage("0200", data).WithEncoder(bitmapFirst{})
+	b, ext, err := msg.BytesWithExtents()
+	assert.Nil(t, err)
+	assert.NotEqual(t, std, b)
+	assert.Equal(t, std[4:12], b[:8])
+	assert.Equal(t, "0200", string(b[8:12]))
+	assert.Equal(t, std[12:], b[12:])
+	_, _, err = ext.Extent(2, 2)
+	assert.NotNil(t, err)
+
+	p := &Parser{Encoder: bitmapFirst{}, RetainRaw: true}
+	assert.Nil(t, p.Register("0200", &TestISO{}))
+	parsed, err := p.Parse(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "0200", parsed.Mti)
+	assert.Equal(t, "000001", parsed.Data.(*TestISO).F11.Value)
+	assert.Equal(t, b, parsed.Raw())
+	again, err := parsed.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, b, again)
+}
@@ -0,0 +1,164 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	registerFeature("pan_masking")
+}
+
+// PANMaskPolicy controls how the PAN (field 2) and the PAN inside track
+// data (fields 35 and 45) are displayed by String, Debug and MarshalJSON
+type PANMaskPolicy int
+
+const (
+	// MaskMiddle6_4 shows the first 6 and last 4 digits of the PAN
+	MaskMiddle6_4 PANMaskPolicy = iota
+	// MaskMiddle_Last4 shows only the last 4 digits of the PAN
+	MaskMiddle_Last4
+	// MaskAll hides every digit of the PAN
+	MaskAll
+)
+
+const defaultMaskChar = '*'
+
+// SetMaskPolicy sets the PAN masking policy of the message
+func (m *Message) SetMaskPolicy(policy PANMaskPolicy) {
+	m.maskPolicy = policy
+}
+
+// SetMaskChar sets the character used for masking, '*' by default
+func (m *Message) SetMaskChar(c byte) {
+	m.maskChar = c
+}
+
+// SetJSONRaw makes MarshalJSON include the bytes retained with RetainRaw,
+// base64 encoded, for audit storage. They are not masked.
+func (m *Message) SetJSONRaw(include bool) {
+	m.jsonRaw = include
+}
+
+func (m *Message) maskWith() byte {
+	if m.maskChar == 0 {
+		return defaultMaskChar
+	}
+	return m.maskChar
+}
+
+// maskPAN masks pan according to the policy of the message
+func (m *Message) maskPAN(pan string) string {
+	c := string(m.maskWith())
+	switch m.maskPolicy {
+	case MaskMiddle6_4:
+		if len(pan) <= 10 {
+			return strings.Repeat(c, len(pan))
+		}
+		return pan[:6] + strings.Repeat(c, len(pan)-10) + pan[len(pan)-4:]
+	case MaskMiddle_Last4:
+		if len(pan) <= 4 {
+			return strings.Repeat(c, len(pan))
+		}
+		return strings.Repeat(c, len(pan)-4) + pan[len(pan)-4:]
+	}
+	return strings.Repeat(c, len(pan))
+}
+
+// maskTrack masks the PAN of track data according to the policy of the
+// message and hides everything after the PAN, keeping the separators.
+// Track 1 data starts with a format code before the PAN.
+func (m *Message) maskTrack(track string, seps string, formatCode bool) string {
+	prefix := ""
+	if formatCode && len(track) > 0 {
+		prefix, track = track[:1], track[1:]
+	}
+	end := strings.IndexAny(track, seps)
+	if end == -1 {
+		return prefix + m.maskPAN(track)
+	}
+	rest := []byte(track[end:])
+	for i := range rest {
+		if strings.IndexByte(seps, rest[i]) == -1 {
+			rest[i] = m.maskWith()
+		}
+	}
+	return prefix + m.maskPAN(track[:end]) + string(rest)
+}
+
+// displayValue returns the value of field n for display, masked if needed
+func (m *Message) displayValue(n int, f Iso8583Type) string {
+	val := fieldString(f)
+	switch n {
+	case 2:
+		return m.maskPAN(val)
+	case 35:
+		return m.maskTrack(val, "=D", false)
+	case 45:
+		return m.maskTrack(val, "^", true)
+	}
+	return val
+}
+
+// String returns a one line representation of the message with sensitive
+// card data masked
+func (m *Message) String() string {
+	ns, fields, err := m.setFields()
+	if err != nil {
+		return m.Mti + " " + err.Error()
+	}
+	parts := make([]string, 0, len(ns))
+	for _, n := range ns {
+		parts = append(parts, fmt.Sprintf("%d=%s", n, m.displayValue(n, fields[n].Field)))
+	}
+	return m.Mti + " [" + strings.Join(parts, " ") + "]"
+}
+
+// Debug returns a multi-line representation of the message: a header with
+// the Version and Features of the package, then its bitmaps in hex with the
+// fields they flag, then every set field with its type, with sensitive card
+// data masked
+func (m *Message) Debug() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n", versionHeader())
+	fmt.Fprintf(&b, "MTI: %s\n", m.Mti)
+	ns, fields, err := m.setFields()
+	if err != nil {
+		fmt.Fprintf(&b, "error: %s\n", err)
+		return b.String()
+	}
+	for _, bm := range m.Bitmaps() {
+		fmt.Fprintf(&b, "Bitmap %s: %X %v\n", bm.Name(), bm.Raw, bm.Fields)
+	}
+	for _, n := range ns {
+		f := fields[n].Field
+		fmt.Fprintf(&b, "%4d %-14s %s\n", n, strings.TrimPrefix(fmt.Sprintf("%T", f), "*iso8583."), m.displayValue(n, f))
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes the MTI and the set fields of the message, with
+// sensitive card data masked, and the raw bytes when asked by SetJSONRaw
+func (m *Message) MarshalJSON() ([]byte, error) {
+	ns, fields, err := m.setFields()
+	if err != nil {
+		return nil, err
+	}
+	values, err := fieldsJSON(ns, func(n int) (string, error) {
+		return m.displayValue(n, fields[n].Field), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var raw []byte
+	if m.jsonRaw {
+		raw = m.raw
+	}
+	return json.Marshal(struct {
+		Mti    string          `json:"mti"`
+		Fields json.RawMessage `json:"fields"`
+		Raw    []byte          `json:"raw,omitempty"`
+	}{m.Mti, values, raw})
+}
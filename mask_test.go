@@ -0,0 +1,123 @@
+package iso8583
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type maskData struct {
+	F2  *Llnumeric    `field:"2" length:"19"`
+	F3  *Numeric      `field:"3" length:"6"`
+	F35 *Llvar        `field:"35" length:"37"`
+	F45 *Llvar        `field:"45" length:"76"`
+	F52 *Binary       `field:"52" length:"8"`
+	F41 *Alphanumeric `field:"41" length:"8"`
+}
+
+func newMaskMessage() *Message {
+	return NewMessage("0200", &maskData{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F35: NewLlvar([]byte("4276555555555555=25121010000012300000")),
+		F45: NewLlvar([]byte("B4276555555555555^DOE/JOHN^2512101")),
+		F41: NewAlphanumeric("TERM0001"),
+	})
+}
+
+func TestMaskPolicy(t *testing.T) {
+	iso := newMaskMessage()
+	assert.Equal(t, "0200 [2=427655******5555 3=000000 35=427655******5555=******************** "+
+		"41=TERM0001 45=B427655******5555^********^*******]", iso.String())
+
+	iso.SetMaskPolicy(MaskMiddle_Last4)
+	assert.Equal(t, "************5555", iso.maskPAN("4276555555555555"))
+
+	iso.SetMaskPolicy(MaskAll)
+	assert.Equal(t, "****************", iso.maskPAN("4276555555555555"))
+
+	iso.SetMaskChar('X')
+	iso.SetMaskPolicy(MaskMiddle6_4)
+	assert.Equal(t, "427655XXXXXX5555", iso.maskPAN("4276555555555555"))
+
+	// short values are masked entirely
+	assert.Equal(t, "XXXXXXXX", iso.maskPAN("42765555"))
+}
+
+func TestMessageDebug(t *testing.T) {
+	iso := newMaskMessage()
+	iso.SetMaskPolicy(MaskMiddle_Last4)
+	assert.Equal(t, versionHeader()+"\n"+
+		"MTI: 0200\n"+
+		"Bitmap primary: 6000000020880000 [2 3 35 41 45]\n"+
+		"   2 Llnumeric      ************5555\n"+
+		"   3 Numeric        000000\n"+
+		"  35 Llvar          ************5555=********************\n"+
+		"  41 Alphanumeric   TERM0001\n"+
+		"  45 Llvar          B************5555^********^*******\n", iso.Debug())
+}
+
+func TestMessageMarshalJSON(t *testing.T) {
+	iso := newMaskMessage()
+	iso.SetMaskPolicy(MaskAll)
+	b, err := iso.MarshalJSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"mti":"0200","fields":{
+		"2":"****************",
+		"3":"000000",
+		"35":"****************=********************",
+		"41":"TERM0001",
+		"45":"B****************^********^*******"}}`, string(b))
+}
+
+func TestMessageMarshalJSONRaw(t *testing.T) {
+	raw, err := newMaskMessage().Bytes()
+	assert.Nil(t, err)
+	p := &Parser{RetainRaw: true}
+	assert.Nil(t, p.Register("0200", &maskData{}))
+	iso, err := p.Parse(raw)
+	assert.Nil(t, err)
+
+	var out struct {
+		Raw []byte `json:"raw"`
+	}
+	b, err := iso.MarshalJSON()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), `"raw"`)
+
+	iso.SetJSONRaw(true)
+	b, err = iso.MarshalJSON()
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"raw":"`+base64.StdEncoding.EncodeToString(raw)+`"`)
+	assert.Nil(t, json.Unmarshal(b, &out))
+	assert.Equal(t, raw, out.Raw)
+
+	// nothing to include when the bytes are not retained
+	iso = newMaskMessage()
+	iso.SetJSONRaw(true)
+	b, err = iso.MarshalJSON()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), `"raw"`)
+}
+
+func TestMessageMarshalJSONDeterministic(t *testing.T) {
+	iso := NewMessage("0200", &TestISO{
+		F2:   NewLlnumeric("4276555555555555"),
+		F3:   NewNumeric("000000"),
+		F11:  NewNumeric("000001"),
+		F41:  NewAlphanumeric("TERM0001"),
+		F120: NewLllnumeric("123"),
+	})
+	iso.SecondBitmap = true
+
+	first, err := iso.MarshalJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"mti":"0200","fields":{"2":"427655******5555","3":"000000","11":"000001","41":"TERM0001","120":"123"}}`, string(first))
+	for i := 0; i < 100; i++ {
+		b, err := iso.MarshalJSON()
+		assert.Nil(t, err)
+		assert.Equal(t, first, b)
+	}
+}
@@ -0,0 +1,194 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+func init() {
+	registerFeature("mcc")
+}
+
+// ErrUnknownMCC is returned by ValidateMCC for codes neither in the table
+// nor registered with RegisterMCC
+var ErrUnknownMCC = errors.New("unknown merchant category code")
+
+// MCCInfo describes a field 18 merchant category code
+type MCCInfo struct {
+	Description string
+	// CashLike marks cash disbursement and quasi-cash categories
+	CashLike bool
+	Gambling bool
+}
+
+// mccMu guards merchantCategoryCodes against RegisterMCC
+var mccMu sync.RWMutex
+
+// merchantCategoryCodes holds the codes described individually, and those
+// added by RegisterMCC. MCCDescription describes the other codes by their
+// range in mccRanges.
+var merchantCategoryCodes = map[string]MCCInfo{
+	"4111": {Description: "Commuter transport"},
+	"4121": {Description: "Taxicabs and limousines"},
+	"4511": {Description: "Airlines"},
+	"4722": {Description: "Travel agencies"},
+	"4814": {Description: "Telecommunication services"},
+	"4829": {Description: "Wire transfers and money orders", CashLike: true},
+	"4900": {Description: "Utilities"},
+	"5311": {Description: "Department stores"},
+	"5411": {Description: "Grocery stores and supermarkets"},
+	"5499": {Description: "Miscellaneous food stores"},
+	"5541": {Description: "Service stations"},
+	"5542": {Description: "Automated fuel dispensers"},
+	"5651": {Description: "Family clothing stores"},
+	"5732": {Description: "Electronics stores"},
+	"5812": {Description: "Eating places and restaurants"},
+	"5813": {Description: "Drinking places"},
+	"5814": {Description: "Fast food restaurants"},
+	"5912": {Description: "Drug stores and pharmacies"},
+	"5999": {Description: "Miscellaneous retail stores"},
+	"6010": {Description: "Financial institutions, manual cash disbursements", CashLike: true},
+	"6011": {Description: "Financial institutions, automated cash disbursements", CashLike: true},
+	"6012": {Description: "Financial institutions, merchandise and services"},
+	"6051": {Description: "Quasi cash", CashLike: true},
+	"6540": {Description: "Stored value card purchase and load", CashLike: true},
+	"7011": {Description: "Hotels and motels"},
+	"7512": {Description: "Car rental agencies"},
+	"7801": {Description: "Government licensed online casinos", Gambling: true},
+	"7802": {Description: "Government licensed horse and dog racing", Gambling: true},
+	"7832": {Description: "Motion picture theaters"},
+	"7995": {Description: "Betting, including lottery tickets and casino chips", Gambling: true, CashLike: true},
+	"8011": {Description: "Doctors"},
+	"8062": {Description: "Hospitals"},
+	"8220": {Description: "Colleges and universities"},
+	"8398": {Description: "Charitable organizations"},
+	"9211": {Description: "Court costs"},
+	"9222": {Description: "Fines"},
+	"9311": {Description: "Tax payments"},
+	"9399": {Description: "Government services"},
+}
+
+// mccRange is a range of codes assigned to one kind of merchant
+type mccRange struct {
+	first, last int
+	description string
+}
+
+// mccRanges are the ranges assigned by ISO 18245, in ascending order. Code
+// 0000 is not assigned.
+var mccRanges = []mccRange{
+	{1, 1499, "Agricultural services"},
+	{1500, 2999, "Contracted services"},
+	{3000, 3299, "Airlines"},
+	{3300, 3499, "Car rental"},
+	{3500, 3999, "Lodging"},
+	{4000, 4799, "Transportation services"},
+	{4800, 4999, "Utility services"},
+	{5000, 5599, "Retail outlet services"},
+	{5600, 5699, "Clothing stores"},
+	{5700, 7299, "Miscellaneous stores"},
+	{7300, 7999, "Business services"},
+	{8000, 8999, "Professional services and membership organizations"},
+	{9000, 9999, "Government services"},
+}
+
+// LookupMCC returns the description of code from the table, including the
+// codes added by RegisterMCC
+func LookupMCC(code string) (info MCCInfo, ok bool) {
+	mccMu.RLock()
+	defer mccMu.RUnlock()
+	info, ok = merchantCategoryCodes[code]
+	return info, ok
+}
+
+// RegisterMCC adds a private merchant category code, or overrides the
+// description of a code in the table. code must be 4 digits. It is safe for
+// concurrent use.
+func RegisterMCC(code string, info MCCInfo) error {
+	if !isMCCFormat(code) {
+		return fmt.Errorf("invalid merchant category code %q", code)
+	}
+	mccMu.Lock()
+	defer mccMu.Unlock()
+	merchantCategoryCodes[code] = info
+	return nil
+}
+
+// ValidateMCC checks that code is 4 digits in the table or registered with
+// RegisterMCC. It returns ErrUnknownMCC for other codes, even within a
+// range assigned by ISO 18245.
+func ValidateMCC(code string) error {
+	if !isMCCFormat(code) {
+		return fmt.Errorf("invalid merchant category code %q", code)
+	}
+	if _, ok := LookupMCC(code); !ok {
+		return fmt.Errorf("%s: %w", code, ErrUnknownMCC)
+	}
+	return nil
+}
+
+// MCCDescription returns the description of code. Codes without an entry
+// of their own are described by their ISO 18245 range, so it is empty only
+// for malformed and unassigned codes.
+func MCCDescription(code string) string {
+	if info, ok := LookupMCC(code); ok {
+		return info.Description
+	}
+	if !isMCCFormat(code) {
+		return ""
+	}
+	n, _ := strconv.Atoi(code)
+	for _, r := range mccRanges {
+		if n >= r.first && n <= r.last {
+			return r.description
+		}
+	}
+	return ""
+}
+
+func isMCCFormat(code string) bool {
+	_, err := checkDigitInput(code)
+	return err == nil && len(code) == 4
+}
+
+// IsCashLike reports whether code is a cash disbursement or quasi-cash
+// category
+func IsCashLike(code string) bool {
+	info, _ := LookupMCC(code)
+	return info.CashLike
+}
+
+// IsGambling reports whether code is a gambling category
+func IsGambling(code string) bool {
+	info, _ := LookupMCC(code)
+	return info.Gambling
+}
+
+// NewMCCEnum returns an Enum restricted to the codes accepted by
+// ValidateMCC, for field 18
+func NewMCCEnum(code string) (*Enum, error) {
+	mccMu.RLock()
+	codes := make([]string, 0, len(merchantCategoryCodes))
+	for c := range merchantCategoryCodes {
+		codes = append(codes, c)
+	}
+	mccMu.RUnlock()
+	return NewEnum(code, codes)
+}
+
+// checkMCC validates field 18 of msg when it is present
+func checkMCC(msg *Message) error {
+	f, err := msg.GetField(18)
+	if errors.Is(err, ErrFieldNotSet) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := ValidateMCC(fieldString(f)); err != nil {
+		return fmt.Errorf("field 18: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMCC(t *testing.T) {
+	assert.Nil(t, ValidateMCC("5411"))
+	assert.Equal(t, "Grocery stores and supermarkets", MCCDescription("5411"))
+	assert.True(t, IsCashLike("6011"))
+	assert.False(t, IsCashLike("5411"))
+	assert.True(t, IsGambling("7995"))
+	assert.False(t, IsGambling("6011"))
+
+	err := ValidateMCC("0000")
+	assert.True(t, errors.Is(err, ErrUnknownMCC))
+	assert.EqualError(t, err, "0000: unknown merchant category code")
+	assert.Equal(t, "", MCCDescription("0000"))
+	assert.False(t, IsCashLike("0000"))
+	assert.EqualError(t, ValidateMCC("541"), `invalid merchant category code "541"`)
+	assert.EqualError(t, ValidateMCC("54a1"), `invalid merchant category code "54a1"`)
+}
+
+func TestLookupMCC(t *testing.T) {
+	info, ok := LookupMCC("5651")
+	assert.True(t, ok)
+	assert.Equal(t, "Family clothing stores", info.Description)
+
+	// codes without an entry of their own are only described by their range
+	for code, description := range map[string]string{
+		"0001": "Agricultural services",
+		"3000": "Airlines",
+		"3351": "Car rental",
+		"3999": "Lodging",
+		"5655": "Clothing stores",
+		"9999": "Government services",
+	} {
+		_, ok := LookupMCC(code)
+		assert.False(t, ok, code)
+		assert.Equal(t, description, MCCDescription(code), code)
+		assert.True(t, errors.Is(ValidateMCC(code), ErrUnknownMCC), code)
+	}
+
+	for _, code := range []string{"0000", "541", "54a1", "05411"} {
+		_, ok := LookupMCC(code)
+		assert.False(t, ok, code)
+		assert.Equal(t, "", MCCDescription(code), code)
+	}
+}
+
+func TestRegisterMCC(t *testing.T) {
+	standard, _ := LookupMCC("5411")
+	t.Cleanup(func() {
+		mccMu.Lock()
+		defer mccMu.Unlock()
+		delete(merchantCategoryCodes, "0742")
+		merchantCategoryCodes["5411"] = standard
+	})
+
+	assert.True(t, errors.Is(ValidateMCC("0742"), ErrUnknownMCC))
+	assert.Nil(t, RegisterMCC("0742", MCCInfo{Description: "Veterinary services"}))
+	assert.Nil(t, ValidateMCC("0742"))
+	assert.Equal(t, "Veterinary services", MCCDescription("0742"))
+	e, err := NewMCCEnum("0742")
+	assert.Nil(t, err)
+	assert.Equal(t, "0742", e.Value)
+
+	// an overridden code
+	assert.Nil(t, RegisterMCC("5411", MCCInfo{Description: "Grocery", CashLike: true}))
+	assert.Equal(t, "Grocery", MCCDescription("5411"))
+	assert.True(t, IsCashLike("5411"))
+
+	assert.EqualError(t, RegisterMCC("74", MCCInfo{}), `invalid merchant category code "74"`)
+}
+
+func TestMCCEnum(t *testing.T) {
+	e, err := NewMCCEnum("5812")
+	assert.Nil(t, err)
+	b, err := e.Bytes(ASCII, ASCII, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, "5812", string(b))
+
+	// codes known by their range only are not allowed
+	_, err = NewMCCEnum("3000")
+	assert.NotNil(t, err)
+}
+
+func TestParserStrictMCC(t *testing.T) {
+	type data struct {
+		F11 *Numeric `field:"11" length:"6" encode:"ascii"`
+		F18 *Numeric `field:"18" length:"4" encode:"ascii"`
+	}
+	unknown, err := NewMessage("0200", &data{F11: NewNumeric("000001"), F18: NewNumeric("0000")}).Bytes()
+	assert.Nil(t, err)
+	absent, err := NewMessage("0200", &data{F11: NewNumeric("000001")}).Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{}
+	assert.Nil(t, p.Register("0200", &data{}))
+	_, err = p.Parse(unknown)
+	assert.Nil(t, err)
+
+	p.StrictMCC = true
+	_, err = p.Parse(unknown)
+	assert.True(t, errors.Is(err, ErrUnknownMCC))
+	assert.EqualError(t, err, "field 18: 0000: unknown merchant category code")
+
+	// a code within an assigned range but not in the table
+	ranged, err := NewMessage("0200", &data{F11: NewNumeric("000001"), F18: NewNumeric("3351")}).Bytes()
+	assert.Nil(t, err)
+	_, err = p.Parse(ranged)
+	assert.EqualError(t, err, "field 18: 3351: unknown merchant category code")
+	_, err = p.Parse(absent)
+	assert.Nil(t, err)
+}
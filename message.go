@@ -1,17 +1,29 @@
 package iso8583
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// ErrFieldNotSet is returned when a field which is read is absent or empty
+var ErrFieldNotSet = errors.New("field not set")
+
 const (
-	TAG_FIELD  string = "field"
-	TAG_ENCODE string = "encode"
-	TAG_LENGTH string = "length"
+	TAG_FIELD   string = "field"
+	TAG_ENCODE  string = "encode"
+	TAG_LENGTH  string = "length"
+	TAG_PACKED  string = "packed"
+	TAG_WIRE    string = "wirebytes"
+	TAG_EVEN    string = "evendigits"
+	TAG_ALLOWED string = "allowed"
+	TAG_CONTENT string = "content"
 )
 
 type fieldInfo struct {
@@ -19,7 +31,37 @@ type fieldInfo struct {
 	Encode    int
 	LenEncode int
 	Length    int
-	Field     Iso8583Type
+
+	// Packed, when not zero, is the number of bytes a bcd or rbcd Numeric
+	// occupies on the wire, for hosts which pack the digits right-aligned
+	// into more bytes than the length needs.
+	Packed int
+
+	// WireBytes, when not zero, is the declared size of a Numeric field on
+	// the wire, cross-checked with the length and encoder
+	WireBytes int
+
+	// EvenDigits rejects odd digit counts in bcd and rbcd numeric fields,
+	// for hosts which refuse padded values
+	EvenDigits bool
+
+	// Allowed, when not nil, restricts an Enum without allowed values of
+	// its own, such as one created by Parser
+	Allowed []string
+
+	// Content, when not EncodingRaw, is the content encoding of an Lllvar
+	// without one of its own, such as one created by Parser
+	Content ContentEncoding
+
+	Field Iso8583Type
+}
+
+func init() {
+	registerFeature("secondary_bitmap")
+	registerFeature("raw_retention")
+	registerFeature("even_digits")
+	registerFeature("extents")
+	registerFeature("max_message_size")
 }
 
 // Message is structure for ISO 8583 message encode and decode
@@ -28,40 +70,151 @@ type Message struct {
 	MtiEncode    int
 	SecondBitmap bool
 	Data         interface{}
+
+	// SecondaryBitmap selects when Bytes emits the secondary bitmap. With
+	// the default SecondaryBitmapManual it follows SecondBitmap.
+	SecondaryBitmap SecondaryBitmapMode
+
+	// RetainRaw keeps a copy of the bytes passed to Load, available via Raw.
+	// It is off by default because it doubles the memory held per message.
+	RetainRaw bool
+
+	// OnField, when set, is called by Load as each field is decoded, in
+	// ascending field order. It is called synchronously, so it must be fast.
+	// The field and raw bytes passed are copies, not views of the decoded
+	// buffer. A panic in OnField is returned by Load as an error.
+	OnField func(n int, f Iso8583Type, raw []byte)
+
+	// Presence, when set, replaces the bitmap for formats which mark the
+	// present fields another way. SecondBitmap is not used then.
+	Presence PresenceScheme
+
+	// Encoder, when set, places the MTI, bitmap and fields of the message
+	// on the wire, see WithEncoder
+	Encoder MessageEncoder
+
+	// MaxMessageSize, when not zero, is the largest size in bytes Bytes may
+	// return. A larger message fails with ErrMessageTooLarge.
+	MaxMessageSize int
+
+	// Rules are constraints between fields, checked by Validate and, as
+	// selected by EnforceRules, by Bytes
+	Rules        Rules
+	EnforceRules RuleEnforcement
+
+	// Faults, when set, injects faults into Bytes and Load. It is for tests
+	// only, see FaultInjector.
+	Faults FaultInjector
+
+	raw        []byte
+	maskPolicy PANMaskPolicy
+	maskChar   byte
+	jsonRaw    bool
+}
+
+// extent is the byte range [start, end) of a message part
+type extent struct {
+	start, end int
 }
 
 // NewMessage creates new Message structure
 func NewMessage(mti string, data interface{}) *Message {
-	return &Message{mti, ASCII, false, data}
+	return &Message{Mti: mti, MtiEncode: ASCII, Data: data}
+}
+
+// Raw returns the bytes the message was loaded from when RetainRaw is set,
+// as given to Load, before any Faults. It is a private copy, so it is not
+// affected by later reuse of the buffer passed to Load.
+func (m *Message) Raw() []byte {
+	return m.raw
 }
 
 // Bytes marshall Message to bytes
-func (m *Message) Bytes() (ret []byte, err error) {
+func (m *Message) Bytes() ([]byte, error) {
+	ret, _, err := m.BytesWithExtents()
+	return ret, err
+}
+
+// BytesWithExtents marshall Message to bytes like Bytes, and returns where
+// the parts of the message were placed in them, as needed for MAC
+// computation. The message itself is not changed. The extents are not
+// known when an Encoder placed the parts or Faults changed the length.
+func (m *Message) BytesWithExtents() (ret []byte, ext Extents, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.New("Critical error:" + fmt.Sprint(r))
 			ret = nil
+			ext = Extents{}
 		}
+		err = encodeError(err)
 	}()
 
-	ret = make([]byte, 0)
+	ret, extents, err := m.encode()
+	if err != nil {
+		return nil, Extents{}, err
+	}
+	if m.MaxMessageSize > 0 && len(ret) > m.MaxMessageSize {
+		return nil, Extents{}, messageTooLarge(len(ret), m.MaxMessageSize, extents)
+	}
+	// with an Encoder, the extents are those of the standard layout, not
+	// of ret
+	if m.Encoder == nil {
+		ext = Extents{parts: extents}
+		if bitmap, ok := extents[1]; ok && m.Presence == nil {
+			ext.bitmaps = readBitmaps(ret[bitmap.start:bitmap.end])
+		}
+	}
+	if m.Faults != nil {
+		n := len(ret)
+		ret = m.Faults.AfterEncode(ret)
+		// the extents do not describe output cut short or grown
+		if len(ret) != n {
+			ext = Extents{}
+		}
+	}
+	return ret, ext, nil
+}
+
+// encode encodes the message and returns the extents of its parts. When an
+// Encoder placed them, the extents are those of the standard layout, which
+// give the size of each part but not its place.
+func (m *Message) encode() ([]byte, map[int]extent, error) {
+	if m.Encoder != nil {
+		return m.encodeWithEncoder()
+	}
+
+	drop, err := m.ruleDrops()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret := make([]byte, 0)
 
 	// generate MTI:
 	mtiBytes, err := m.encodeMti()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	ret = append(ret, mtiBytes...)
 
+	if m.Presence != nil {
+		return m.encodeWithPresence(mtiBytes, drop)
+	}
+
 	// generate bitmap and fields:
 	fields := parseFields(m.Data)
+	second, err := m.secondBitmap(fields, drop)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	byteNum := 8
-	if m.SecondBitmap {
+	if second {
 		byteNum = 16
 	}
 	bitmap := make([]byte, byteNum)
 	data := make([]byte, 0, 512)
+	extents := make(map[int]extent)
 
 	for byteIndex := 0; byteIndex < byteNum; byteIndex++ {
 		for bitIndex := 0; bitIndex < 8; bitIndex++ {
@@ -69,7 +222,7 @@ func (m *Message) Bytes() (ret []byte, err error) {
 			i := byteIndex*8 + bitIndex + 1
 
 			// if we need second bitmap (additional 8 bytes) - set first bit in first bitmap
-			if m.SecondBitmap && i == 1 {
+			if second && i == 1 {
 				step := uint(7 - bitIndex)
 				bitmap[byteIndex] |= (0x01 << step)
 			}
@@ -77,7 +230,7 @@ func (m *Message) Bytes() (ret []byte, err error) {
 			if info, ok := fields[i]; ok {
 
 				// if field is empty, then we can't add it to bitmap
-				if info.Field.IsEmpty() {
+				if info.Field.IsEmpty() || drop[i] {
 					continue
 				}
 
@@ -85,10 +238,14 @@ func (m *Message) Bytes() (ret []byte, err error) {
 				step := uint(7 - bitIndex)
 				bitmap[byteIndex] |= (0x01 << step)
 				// append data:
-				d, err := info.Field.Bytes(info.Encode, info.LenEncode, info.Length)
+				d, err := info.bytes()
 				if err != nil {
-					return nil, err
+					return nil, nil, err
+				}
+				if m.Faults != nil {
+					d = m.Faults.AfterField(i, d)
 				}
+				extents[i] = extent{len(data), len(data) + len(d)}
 				data = append(data, d...)
 			}
 		}
@@ -96,7 +253,52 @@ func (m *Message) Bytes() (ret []byte, err error) {
 	ret = append(ret, bitmap...)
 	ret = append(ret, data...)
 
-	return ret, nil
+	// record where the MTI (0), the bitmap (1) and every field were placed
+	head := len(mtiBytes) + len(bitmap)
+	for i, e := range extents {
+		extents[i] = extent{e.start + head, e.end + head}
+	}
+	extents[0] = extent{0, len(mtiBytes)}
+	extents[1] = extent{len(mtiBytes), head}
+
+	return ret, extents, nil
+}
+
+// encodeWithPresence encodes the fields after mtiBytes, marked by the
+// Presence scheme of the message, leaving out the fields in drop, and
+// returns their extents
+func (m *Message) encodeWithPresence(mtiBytes []byte, drop map[int]bool) ([]byte, map[int]extent, error) {
+	set, fields, err := m.setFields()
+	if err != nil {
+		return nil, nil, err
+	}
+	ns := make([]int, 0, len(set))
+	for _, n := range set {
+		if !drop[n] {
+			ns = append(ns, n)
+		}
+	}
+	presence, order, err := encodePresence(m.Presence, ns)
+	if err != nil {
+		return nil, nil, err
+	}
+	ret := append(append([]byte{}, mtiBytes...), presence...)
+	extents := map[int]extent{
+		0: {0, len(mtiBytes)},
+		1: {len(mtiBytes), len(ret)},
+	}
+	for _, n := range order {
+		d, err := fields[n].bytes()
+		if err != nil {
+			return nil, nil, err
+		}
+		if m.Faults != nil {
+			d = m.Faults.AfterField(n, d)
+		}
+		extents[n] = extent{len(ret), len(ret) + len(d)}
+		ret = append(ret, d...)
+	}
+	return ret, extents, nil
 }
 
 func (m *Message) encodeMti() ([]byte, error) {
@@ -162,15 +364,351 @@ func parseFields(msg interface{}) map[int]*fieldInfo {
 			}
 		}
 
+		packed := 0
+		if p := sf.Tag.Get(TAG_PACKED); p != "" {
+			packed, err = strconv.Atoi(p)
+			if err != nil {
+				panic("value of packed must be numeric")
+			}
+		}
+
+		wire := 0
+		if w := sf.Tag.Get(TAG_WIRE); w != "" {
+			wire, err = strconv.Atoi(w)
+			if err != nil {
+				panic("value of wirebytes must be numeric")
+			}
+		}
+
+		even := false
+		if e := sf.Tag.Get(TAG_EVEN); e != "" {
+			even, err = strconv.ParseBool(e)
+			if err != nil {
+				panic("value of evendigits must be a boolean")
+			}
+		}
+
+		var allowed []string
+		if a := sf.Tag.Get(TAG_ALLOWED); a != "" {
+			allowed = strings.Split(a, ",")
+		}
+
+		content := EncodingRaw
+		if c := sf.Tag.Get(TAG_CONTENT); c != "" {
+			content = parseContentEncoding(c)
+			if content < 0 {
+				panic("value of content must be raw, utf8, latin1, bcd or besteffort")
+			}
+		}
+
 		field, ok := v.Field(i).Interface().(Iso8583Type)
 		if !ok {
 			panic("field must be Iso8583Type")
 		}
-		fields[index] = &fieldInfo{index, encode, lenEncode, length, field}
+		fields[index] = &fieldInfo{
+			Index:      index,
+			Encode:     encode,
+			LenEncode:  lenEncode,
+			Length:     length,
+			Packed:     packed,
+			WireBytes:  wire,
+			EvenDigits: even,
+			Allowed:    allowed,
+			Content:    content,
+			Field:      field,
+		}
 	}
 	return fields
 }
 
+// bytes encodes the field, applying the packed length if there is one and
+// checking the declared wire size
+func (f *fieldInfo) bytes() ([]byte, error) {
+	if err := f.checkEvenDigits(); err != nil {
+		return nil, err
+	}
+	if e, ok := f.Field.(*Enum); ok && f.Allowed != nil {
+		if err := e.withAllowed(f.Allowed).check(e.Value); err != nil {
+			return nil, err
+		}
+	}
+	d, err := f.encode()
+	if err != nil || f.WireBytes == 0 {
+		return d, err
+	}
+	if _, ok := f.Field.(*Numeric); !ok {
+		return nil, fmt.Errorf("field %d: wirebytes is only supported for Numeric fields", f.Index)
+	}
+	if err := checkWireBytes(f.Index, f.Encode, f.Length, f.Packed, f.WireBytes); err != nil {
+		return nil, err
+	}
+	if len(d) != f.WireBytes {
+		return nil, fmt.Errorf("field %d: encoded to %d bytes, but wirebytes is %d", f.Index, len(d), f.WireBytes)
+	}
+	return d, nil
+}
+
+func (f *fieldInfo) encode() ([]byte, error) {
+	if f.Packed == 0 {
+		return f.Field.Bytes(f.Encode, f.LenEncode, f.Length)
+	}
+	n, err := f.packedNumeric()
+	if err != nil {
+		return nil, err
+	}
+	if digits := len(n.Digits()); digits > f.Length {
+		return nil, &ErrValueTooLong{"Numeric", f.Length, digits}
+	}
+	return n.Bytes(rBCD, f.LenEncode, f.Packed*2)
+}
+
+// load decodes the field, applying the packed length if there is one. Of
+// the packed digits only the rightmost Length digits are kept; the others
+// must be zero.
+func (f *fieldInfo) load(raw []byte) (int, error) {
+	if e, ok := f.Field.(*Enum); ok && e.allowed == nil && f.Allowed != nil {
+		e.setAllowed(f.Allowed)
+	}
+	if l, ok := f.Field.(*Lllvar); ok && l.Encoding == EncodingRaw {
+		l.Encoding = f.Content
+	}
+	if f.Packed == 0 {
+		return f.Field.Load(raw, f.Encode, f.LenEncode, f.Length)
+	}
+	n, err := f.packedNumeric()
+	if err != nil {
+		return 0, err
+	}
+	packed := &Numeric{}
+	read, err := packed.Load(raw, rBCD, f.LenEncode, f.Packed*2)
+	if err != nil {
+		return 0, err
+	}
+	pad := len(packed.Value) - f.Length
+	if strings.Trim(packed.Value[:pad], "0") != "" {
+		return 0, &ErrValueTooLong{"Numeric", f.Length, len(strings.TrimLeft(packed.Value, "0"))}
+	}
+	*n = Numeric{Value: packed.Value[pad:]}
+	return read, nil
+}
+
+// checkEvenDigits checks that a BCD numeric field tagged evendigits is
+// encoded as an even number of digits: the length of a fixed Numeric, which
+// is zero padded to it, or the value of a variable length field
+func (f *fieldInfo) checkEvenDigits() error {
+	if !f.EvenDigits || (f.Encode != BCD && f.Encode != rBCD) {
+		return nil
+	}
+	var digits int
+	switch field := f.Field.(type) {
+	case *Numeric:
+		if f.Packed != 0 {
+			// the digits are padded to whole packed bytes
+			return nil
+		}
+		digits = f.Length
+	case *Llnumeric:
+		digits = len(field.Value)
+	case *Lllnumeric:
+		digits = len(field.Value)
+	case *Llllnumeric:
+		digits = len(field.Value)
+	default:
+		return nil
+	}
+	if digits%2 != 0 {
+		return &ErrOddDigitCount{Field: f.Index, Len: digits}
+	}
+	return nil
+}
+
+// checkWireBytes checks that a Numeric field of length digits takes wire
+// bytes with the encoder, catching lengths given in bytes instead of digits
+func checkWireBytes(index, encode, length, packed, wire int) error {
+	size, digits := length, wire
+	switch {
+	case packed != 0:
+		size, digits = packed, 2*wire
+	case encode == BCD || encode == rBCD:
+		size, digits = (length+1)/2, 2*wire
+	}
+	if size != wire {
+		return fmt.Errorf("field %d: length %d digits takes %d bytes with %s encoding, but wirebytes %d holds %d digits",
+			index, length, size, encodeName(encode), wire, digits)
+	}
+	return nil
+}
+
+// checkTemplateWireBytes checks the wirebytes tags of a message template
+func checkTemplateWireBytes(t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		w := sf.Tag.Get(TAG_WIRE)
+		if w == "" {
+			continue
+		}
+		index, _ := strconv.Atoi(sf.Tag.Get(TAG_FIELD))
+		if sf.Type != reflect.TypeOf(&Numeric{}) {
+			return fmt.Errorf("field %d: wirebytes is only supported for Numeric fields", index)
+		}
+		wire, err := strconv.Atoi(w)
+		if err != nil {
+			return fmt.Errorf("field %d: value of wirebytes must be numeric", index)
+		}
+		length, err := strconv.Atoi(sf.Tag.Get(TAG_LENGTH))
+		if err != nil {
+			return fmt.Errorf("field %d: %w", index, &ErrMissingLength{})
+		}
+		packed, _ := strconv.Atoi(sf.Tag.Get(TAG_PACKED))
+		encode := ASCII
+		if raw := sf.Tag.Get(TAG_ENCODE); raw != "" {
+			enc := strings.Split(raw, ",")
+			encode = parseEncodeStr(enc[len(enc)-1])
+		}
+		if err := checkWireBytes(index, encode, length, packed, wire); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fieldInfo) packedNumeric() (*Numeric, error) {
+	n, ok := f.Field.(*Numeric)
+	if !ok || (f.Encode != BCD && f.Encode != rBCD) {
+		return nil, errors.New("packed length is only supported for bcd and rbcd Numeric fields")
+	}
+	if f.Length == -1 {
+		return nil, &ErrMissingLength{}
+	}
+	if f.Packed*2 < f.Length {
+		return nil, fmt.Errorf("packed length %d is too short for length %d", f.Packed, f.Length)
+	}
+	return n, nil
+}
+
+// structField returns the settable field of data tagged with index n. Nil
+// pointer fields are initialized, so the returned field can always be used
+// as Iso8583Type unless it is a nil interface.
+func structField(data interface{}, n int) (reflect.Value, error) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("data must be a pointer to struct")
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if sf.Tag.Get(TAG_FIELD) != strconv.Itoa(n) {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			f.Set(reflect.New(sf.Type.Elem()))
+		}
+		if f.Kind() == reflect.Interface && f.IsNil() {
+			return reflect.Value{}, fmt.Errorf("field %d has no concrete type", n)
+		}
+		if _, ok := f.Interface().(Iso8583Type); !ok {
+			return reflect.Value{}, fmt.Errorf("field %d must be Iso8583Type", n)
+		}
+		return f, nil
+	}
+	return reflect.Value{}, fmt.Errorf("field %d not defined", n)
+}
+
+// setFieldString sets the value of field n of data from its string form.
+// Binary, Llbinary and Lllbinary values are given in hex.
+func setFieldString(data interface{}, n int, value string) error {
+	f, err := structField(data, n)
+	if err != nil {
+		return err
+	}
+	switch field := f.Interface().(type) {
+	case *Numeric:
+		*field = Numeric{Value: value}
+	case *Alphanumeric:
+		field.Value = value
+	case *Enum:
+		field.Value = value
+	case *Llnumeric:
+		field.Value = value
+	case *Lllnumeric:
+		field.Value = value
+	case *Llllnumeric:
+		field.Value = value
+	case *Llalpha:
+		field.Value = value
+	case *Lllalpha:
+		field.Value = value
+	case *Llvar:
+		field.Value = []byte(value)
+	case *Lllvar:
+		field.Value = []byte(value)
+	case *Llllvar:
+		field.Value = []byte(value)
+	case *Binary:
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("field %d: %s", n, err)
+		}
+		field.Value = b
+		field.FixLen = -1
+	case *Llbinary:
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("field %d: %s", n, err)
+		}
+		field.Value = b
+	case *Lllbinary:
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("field %d: %s", n, err)
+		}
+		field.Value = b
+	default:
+		return fmt.Errorf("field %d: unsupported type %T", n, field)
+	}
+	return nil
+}
+
+// fieldString returns the value of a field as a string. Binary, Llbinary and
+// Lllbinary values are returned in hex, best effort Lllvar values as decoded
+// by String.
+func fieldString(f Iso8583Type) string {
+	switch field := f.(type) {
+	case *Numeric:
+		return field.Digits()
+	case *Alphanumeric:
+		return field.Value
+	case *Enum:
+		return field.Value
+	case *Llnumeric:
+		return field.Value
+	case *Lllnumeric:
+		return field.Value
+	case *Llllnumeric:
+		return field.Value
+	case *Llalpha:
+		return field.Value
+	case *Lllalpha:
+		return field.Value
+	case *Llvar:
+		return string(field.Value)
+	case *Lllvar:
+		return field.text()
+	case *Llllvar:
+		return string(field.Value)
+	case *Binary:
+		return field.String()
+	case *Llbinary:
+		return field.String()
+	case *Lllbinary:
+		return field.String()
+	case *TaggedLLLField:
+		return string(field.payload())
+	}
+	return fmt.Sprint(f)
+}
+
 func isPtrOrInterface(k reflect.Kind) bool {
 	return k == reflect.Interface || k == reflect.Ptr
 }
@@ -185,22 +723,69 @@ func parseEncodeStr(str string) int {
 		return BCD
 	case "rbcd":
 		return rBCD
+	case "binlen2":
+		return BinaryLen2
+	case "binlen2le":
+		return BinaryLen2LE
+	case "zbcd":
+		return ZoneBCD
+	case "ebcdic":
+		return EBCDIC
 	}
 	return -1
 }
 
 // Load unmarshall Message from bytes
-func (m *Message) Load(raw []byte) (err error) {
+func (m *Message) Load(raw []byte) error {
+	_, _, err := m.load(raw)
+	return err
+}
+
+// LoadWithExtents is Load which also returns where the parts of the
+// message were found in raw, and the bitmaps as they were read. The
+// extents of the parts are not known when an Encoder is set.
+func (m *Message) LoadWithExtents(raw []byte) (Extents, error) {
+	_, ext, err := m.load(raw)
+	if m.Encoder != nil {
+		ext.parts = nil
+	}
+	return ext, err
+}
+
+// load decodes raw into the message and returns the number of bytes read,
+// the extents of the parts in the standard layout and the bitmaps read.
+// Trailing bytes are not read.
+func (m *Message) load(raw []byte) (n int, ext Extents, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.New("Critical error:" + fmt.Sprint(r))
 		}
+		err = decodeError(err)
+		if err != nil {
+			n, ext = 0, Extents{}
+		}
 	}()
 
+	m.raw = nil
+	if m.RetainRaw {
+		m.raw = copyBytes(raw)
+	}
+	if m.Faults != nil {
+		raw = m.Faults.BeforeDecode(raw)
+	}
+	// the bytes left unread by the fields end both raw and its layout
+	size := len(raw)
+	if m.Encoder != nil {
+		if raw, err = standardLayout(m.Encoder, raw, m.MtiEncode); err != nil {
+			return 0, Extents{}, err
+		}
+	}
+	extents := make(map[int]extent)
+
 	if m.Mti == "" {
 		m.Mti, err = decodeMti(raw, m.MtiEncode)
 		if err != nil {
-			return err
+			return 0, Extents{}, err
 		}
 	}
 	start := 4
@@ -209,6 +794,25 @@ func (m *Message) Load(raw []byte) (err error) {
 	}
 
 	fields := parseFields(m.Data)
+	extents[0] = extent{0, start}
+
+	if m.Presence != nil {
+		ns, l, err := m.Presence.DecodePresence(raw[start:])
+		if err != nil {
+			return 0, Extents{}, err
+		}
+		extents[1] = extent{start, start + l}
+		start += l
+		for _, i := range ns {
+			l, err := m.loadField(fields, i, raw, start)
+			if err != nil {
+				return 0, Extents{}, err
+			}
+			extents[i] = extent{start, start + l}
+			start += l
+		}
+		return size - (len(raw) - start), Extents{parts: extents}, nil
+	}
 
 	byteNum := 8
 	if raw[start]&0x80 == 0x80 {
@@ -217,6 +821,7 @@ func (m *Message) Load(raw []byte) (err error) {
 		byteNum = 16
 	}
 	bitByte := raw[start : start+byteNum]
+	extents[1] = extent{start, start + byteNum}
 	start += byteNum
 
 	for byteIndex := 0; byteIndex < byteNum; byteIndex++ {
@@ -231,16 +836,374 @@ func (m *Message) Load(raw []byte) (err error) {
 				// field 1 is the second bitmap
 				continue
 			}
-			f, ok := fields[i]
-			if !ok {
-				return fmt.Errorf("field %d not defined", i)
-			}
-			l, err := f.Field.Load(raw[start:], f.Encode, f.LenEncode, f.Length)
+			l, err := m.loadField(fields, i, raw, start)
 			if err != nil {
-				return fmt.Errorf("field %d: %s", i, err)
+				return 0, Extents{}, err
 			}
+			extents[i] = extent{start, start + l}
 			start += l
 		}
 	}
+	return size - (len(raw) - start), Extents{extents, readBitmaps(bitByte)}, nil
+}
+
+// loadField decodes field i from raw at start and returns its size
+func (m *Message) loadField(fields map[int]*fieldInfo, i int, raw []byte, start int) (int, error) {
+	f, ok := fields[i]
+	if !ok {
+		return 0, fmt.Errorf("field %d not defined", i)
+	}
+	l, err := f.load(raw[start:])
+	if err != nil {
+		return 0, fmt.Errorf("field %d: %w", i, err)
+	}
+	if m.OnField != nil {
+		if err := callOnField(m.OnField, i, f.Field, raw[start:start+l]); err != nil {
+			return 0, err
+		}
+	}
+	return l, nil
+}
+
+func callOnField(fn func(int, Iso8583Type, []byte), n int, f Iso8583Type, raw []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("field %d: OnField panicked: %v", n, r)
+		}
+	}()
+	fn(n, f.Copy(), copyBytes(raw))
+	return nil
+}
+
+// Clone returns a deep copy of the message. Every field of Data is copied,
+// so the clone can be modified without affecting the original message.
+// The retained raw bytes are not part of the clone.
+func (m *Message) Clone() *Message {
+	c := *m
+	c.raw = nil
+	if m.Data != nil {
+		c.Data = cloneData(m.Data)
+	}
+	return &c
+}
+
+func cloneData(data interface{}) interface{} {
+	src := reflect.ValueOf(data)
+	isPtr := src.Kind() == reflect.Ptr
+	if isPtr && src.IsNil() {
+		return data
+	}
+	src = reflect.Indirect(src)
+	if src.Kind() != reflect.Struct {
+		return data
+	}
+
+	dst := reflect.New(src.Type())
+	dst.Elem().Set(src)
+	for i := 0; i < src.NumField(); i++ {
+		f := dst.Elem().Field(i)
+		if !f.CanSet() || !isPtrOrInterface(f.Kind()) || f.IsNil() {
+			continue
+		}
+		if field, ok := f.Interface().(Iso8583Type); ok {
+			f.Set(reflect.ValueOf(field.Copy()))
+		}
+	}
+
+	if isPtr {
+		return dst.Interface()
+	}
+	return dst.Elem().Interface()
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+// CopyField deep-copies field srcField of the message into field dstField of
+// dst. It returns ErrFieldNotSet if srcField is not set, and an error if the
+// two fields are of different types.
+func (m *Message) CopyField(srcField int, dst *Message, dstField int) error {
+	fields, err := m.fields()
+	if err != nil {
+		return err
+	}
+	return m.copyField(fields, srcField, dst, dstField)
+}
+
+// CopyFields deep-copies fields of the message into dst according to
+// mapping, which maps source field numbers to destination field numbers.
+func (m *Message) CopyFields(mapping map[int]int, dst *Message) error {
+	fields, err := m.fields()
+	if err != nil {
+		return err
+	}
+	srcs := make([]int, 0, len(mapping))
+	for src := range mapping {
+		srcs = append(srcs, src)
+	}
+	sort.Ints(srcs)
+	for _, src := range srcs {
+		if err := m.copyField(fields, src, dst, mapping[src]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Message) copyField(fields map[int]*fieldInfo, srcField int, dst *Message, dstField int) error {
+	info, ok := fields[srcField]
+	if !ok || info.Field.IsEmpty() {
+		return fmt.Errorf("field %d: %w", srcField, ErrFieldNotSet)
+	}
+	f, err := structField(dst.Data, dstField)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(info.Field.Copy())
+	if !v.Type().AssignableTo(f.Type()) {
+		return fmt.Errorf("field %d: cannot copy %s into %s", dstField, v.Type(), f.Type())
+	}
+	f.Set(v)
 	return nil
 }
+
+// fields parses the fields of Data, converting a parse panic into an error
+func (m *Message) fields() (fields map[int]*fieldInfo, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	return parseFields(m.Data), nil
+}
+
+// GetField returns field n of the message. It returns ErrFieldNotSet if the
+// field is absent or empty.
+func (m *Message) GetField(n int) (Iso8583Type, error) {
+	fields, err := m.fields()
+	if err != nil {
+		return nil, err
+	}
+	info, ok := fields[n]
+	if !ok || info.Field.IsEmpty() {
+		return nil, fmt.Errorf("field %d: %w", n, ErrFieldNotSet)
+	}
+	return info.Field, nil
+}
+
+// SetFieldFromString sets field n of the message from value. The type of the
+// field is the one declared in Data, which must be a pointer to struct; a nil
+// field is allocated. Binary, Llbinary and Lllbinary fields are set with
+// SetFieldFromHex.
+func (m *Message) SetFieldFromString(n int, value string) error {
+	f, err := structField(m.Data, n)
+	if err != nil {
+		return err
+	}
+	switch f.Interface().(type) {
+	case *Binary, *Llbinary, *Lllbinary:
+		return fmt.Errorf("field %d: %s field must be set with SetFieldFromHex", n, f.Type().Elem().Name())
+	}
+	return setFieldString(m.Data, n, value)
+}
+
+// SetFieldFromHex sets Binary, Llbinary or Lllbinary field n of the message
+// from its hex form
+func (m *Message) SetFieldFromHex(n int, hexValue string) error {
+	f, err := structField(m.Data, n)
+	if err != nil {
+		return err
+	}
+	switch f.Interface().(type) {
+	case *Binary, *Llbinary, *Lllbinary:
+	default:
+		return fmt.Errorf("field %d: %s is not a Binary field", n, f.Type())
+	}
+	return setFieldString(m.Data, n, hexValue)
+}
+
+// GetString returns the value of field n as a string. Binary values are
+// returned in hex.
+func (m *Message) GetString(n int) (string, error) {
+	f, err := m.GetField(n)
+	if err != nil {
+		return "", err
+	}
+	return fieldString(f), nil
+}
+
+// GetFields returns the listed fields of the message by field number. It
+// returns ErrFieldNotSet naming every listed field which is absent or empty.
+func (m *Message) GetFields(ns ...int) (map[int]Iso8583Type, error) {
+	fields, err := m.fields()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[int]Iso8583Type, len(ns))
+	var missing []string
+	for _, n := range ns {
+		info, ok := fields[n]
+		if !ok || info.Field.IsEmpty() {
+			missing = append(missing, strconv.Itoa(n))
+			continue
+		}
+		ret[n] = info.Field
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("fields %s: %w", strings.Join(missing, ", "), ErrFieldNotSet)
+	}
+	return ret, nil
+}
+
+// GetAvailableFields returns the listed fields of the message by field
+// number, silently skipping fields which are absent or empty.
+func (m *Message) GetAvailableFields(ns ...int) map[int]Iso8583Type {
+	ret := make(map[int]Iso8583Type, len(ns))
+	fields, err := m.fields()
+	if err != nil {
+		return ret
+	}
+	for _, n := range ns {
+		if info, ok := fields[n]; ok && !info.Field.IsEmpty() {
+			ret[n] = info.Field
+		}
+	}
+	return ret
+}
+
+// BitCount returns the number of data fields set in the message. The
+// bitmap indicator bits (1 and 65) are not counted.
+func (m *Message) BitCount() int {
+	fields, err := m.fields()
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for i, info := range fields {
+		if i != 1 && i != 65 && !info.Field.IsEmpty() {
+			count++
+		}
+	}
+	return count
+}
+
+// FindField returns the first set field, in field number order, for which
+// predicate returns true. found is false if there is none.
+func (m *Message) FindField(predicate func(int, Iso8583Type) bool) (n int, field Iso8583Type, found bool) {
+	fields, all := m.findFields(predicate, true)
+	if len(all) == 0 {
+		return 0, nil, false
+	}
+	return all[0], fields[all[0]].Field, true
+}
+
+// FindAllFields returns the numbers of all set fields for which predicate
+// returns true, in ascending order.
+func (m *Message) FindAllFields(predicate func(int, Iso8583Type) bool) []int {
+	_, found := m.findFields(predicate, false)
+	return found
+}
+
+func (m *Message) findFields(predicate func(int, Iso8583Type) bool, first bool) (map[int]*fieldInfo, []int) {
+	ns, fields, err := m.setFields()
+	if err != nil {
+		return nil, nil
+	}
+
+	var found []int
+	for _, n := range ns {
+		if !predicate(n, fields[n].Field) {
+			continue
+		}
+		found = append(found, n)
+		if first {
+			break
+		}
+	}
+	return fields, found
+}
+
+// setFields returns the numbers of the set fields of the message in
+// ascending order, with the fields. Everything the package writes out
+// iterates fields through it, so output does not depend on map order.
+func (m *Message) setFields() ([]int, map[int]*fieldInfo, error) {
+	fields, err := m.fields()
+	if err != nil {
+		return nil, nil, err
+	}
+	ns := make([]int, 0, len(fields))
+	for n, info := range fields {
+		if !info.Field.IsEmpty() {
+			ns = append(ns, n)
+		}
+	}
+	sort.Ints(ns)
+	return ns, fields, nil
+}
+
+// fieldsJSON encodes a JSON object with the field numbers ns as keys, in
+// the order given, and value(n) as values. encoding/json sorts map keys as
+// strings, which would put field 11 before field 2.
+func fieldsJSON(ns []int, value func(n int) (string, error)) (json.RawMessage, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, n := range ns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		s, err := value(n)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "\"%d\":", n)
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Extents holds where the parts of an encoded or decoded message were
+// placed, and its bitmaps as they were on the wire. Field 0 is the MTI and
+// field 1 the bitmap.
+type Extents struct {
+	parts   map[int]extent
+	bitmaps []BitmapInfo
+}
+
+// Bitmaps returns the bitmaps as they were read or emitted, in wire order.
+// Unlike Message.Bitmaps it reports an all zero secondary bitmap which was
+// read. It returns nil when Presence replaces the bitmap or the bitmaps
+// are not known.
+func (e Extents) Bitmaps() []BitmapInfo {
+	return e.bitmaps
+}
+
+// Extent returns the byte range [start, end) covering fields fromField to
+// toField, inclusive. Extent(0, n) covers the message from its start to the
+// end of field n, as needed for MAC computation.
+func (e Extents) Extent(fromField, toField int) (start, end int, err error) {
+	if e.parts == nil {
+		return 0, 0, errors.New("extents are not known")
+	}
+	from, ok := e.parts[fromField]
+	if !ok {
+		return 0, 0, fmt.Errorf("field %d: %w", fromField, ErrFieldNotSet)
+	}
+	to, ok := e.parts[toField]
+	if !ok {
+		return 0, 0, fmt.Errorf("field %d: %w", toField, ErrFieldNotSet)
+	}
+	if to.end < from.start {
+		return 0, 0, fmt.Errorf("field %d is placed before field %d", toField, fromField)
+	}
+	return from.start, to.end, nil
+}
@@ -0,0 +1,102 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+func init() {
+	registerFeature("middleware")
+}
+
+// Handler processes a message and returns the response to it, if any
+type Handler func(ctx context.Context, msg *Message) (*Message, error)
+
+// Middleware wraps a Handler with a processing step, such as validation or
+// logging. A middleware returning an error without calling next stops the
+// chain.
+type Middleware func(next Handler) Handler
+
+// Chain wraps h with middlewares. The first middleware is the outermost
+// one, so it sees the message first and the response last.
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Mux dispatches messages to a handler chain by MTI. It is safe for
+// concurrent use, so handlers can be registered while serving.
+type Mux struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewMux create new Mux
+func NewMux() *Mux {
+	return &Mux{handlers: make(map[string]Handler)}
+}
+
+// Handle registers h, wrapped with middlewares, for messages of mti. It
+// replaces a handler already registered for mti.
+func (mux *Mux) Handle(mti string, h Handler, middlewares ...Middleware) {
+	h = Chain(h, middlewares...)
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	mux.handlers[mti] = h
+}
+
+// Serve passes msg to the handler chain of its MTI. It returns an error
+// for MTIs without a handler.
+func (mux *Mux) Serve(ctx context.Context, msg *Message) (*Message, error) {
+	if msg == nil {
+		return nil, errors.New("message is required")
+	}
+	mux.mu.RLock()
+	h, ok := mux.handlers[msg.Mti]
+	mux.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no handler for MTI %s", msg.Mti)
+	}
+	return h(ctx, msg)
+}
+
+// ValidateMiddleware rejects messages breaking rules, with the error of
+// Validate. The Rules of the message are not used, so it works for parsed
+// messages, which have none.
+func ValidateMiddleware(rules Rules) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			set, err := msg.presentFields()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := rules.check(set, false); err != nil {
+				return nil, err
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+// RedactLoggingMiddleware logs every message and its response or error to
+// logger, using String, so card data is masked
+func RedactLoggingMiddleware(logger *log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			logger.Printf("-> %s", msg)
+			resp, err := next(ctx, msg)
+			switch {
+			case err != nil:
+				logger.Printf("<- %s error: %s", msg.Mti, err)
+			case resp != nil:
+				logger.Printf("<- %s", resp)
+			}
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package iso8583
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func traceMiddleware(trace *[]string, name string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			*trace = append(*trace, name+" in")
+			resp, err := next(ctx, msg)
+			*trace = append(*trace, name+" out")
+			return resp, err
+		}
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var trace []string
+	h := Chain(func(ctx context.Context, msg *Message) (*Message, error) {
+		trace = append(trace, "handler")
+		return NewMessage("0210", msg.Data), nil
+	}, traceMiddleware(&trace, "a"), traceMiddleware(&trace, "b"), traceMiddleware(&trace, "c"))
+
+	resp, err := h(context.Background(), NewMessage("0200", &rulesData{}))
+	assert.Nil(t, err)
+	assert.Equal(t, "0210", resp.Mti)
+	assert.Equal(t, []string{"a in", "b in", "c in", "handler", "c out", "b out", "a out"}, trace)
+}
+
+func TestChainShortCircuit(t *testing.T) {
+	var trace []string
+	reject := func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			trace = append(trace, "reject")
+			return nil, errors.New("rejected")
+		}
+	}
+	h := Chain(func(ctx context.Context, msg *Message) (*Message, error) {
+		trace = append(trace, "handler")
+		return msg, nil
+	}, traceMiddleware(&trace, "a"), reject, traceMiddleware(&trace, "c"))
+
+	_, err := h(context.Background(), NewMessage("0200", &rulesData{}))
+	assert.EqualError(t, err, "rejected")
+	assert.Equal(t, []string{"a in", "reject", "a out"}, trace)
+}
+
+func TestMux(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	called := false
+	mux := NewMux()
+	mux.Handle("0200", func(ctx context.Context, msg *Message) (*Message, error) {
+		called = true
+		return NewMessage("0210", msg.Data), nil
+	}, RedactLoggingMiddleware(logger), ValidateMiddleware(Rules{
+		Exclusive(Group(35), Group(2, 14)),
+		Requires(23, 2),
+	}))
+
+	// rules are broken, the handler is not called
+	msg, data := newRulesMessage()
+	msg.Rules = nil
+	_, err := mux.Serve(context.Background(), msg)
+	assert.EqualError(t, err, "fields 35 and 2+14 are exclusive")
+	assert.False(t, called)
+	assert.Equal(t, "-> 0200 [2=411111******1111 3=000000 14=2512 35=411111******1111=****]\n"+
+		"<- 0200 error: fields 35 and 2+14 are exclusive\n", buf.String())
+
+	buf.Reset()
+	data.F35 = nil
+	resp, err := mux.Serve(context.Background(), msg)
+	assert.Nil(t, err)
+	assert.True(t, called)
+	assert.Equal(t, "0210", resp.Mti)
+	assert.True(t, strings.HasPrefix(buf.String(), "-> 0200 [2=411111******1111 3=000000 14=2512]\n<- 0210 ["))
+
+	_, err = mux.Serve(context.Background(), NewMessage("0800", &rulesData{}))
+	assert.EqualError(t, err, "no handler for MTI 0800")
+}
+
+func TestValidateMiddlewareParsed(t *testing.T) {
+	raw, err := NewMessage("0200", &rulesData{
+		F23: NewNumeric("001"),
+		F35: NewLlvar([]byte("4111111111111111=2512")),
+	}).Bytes()
+	assert.Nil(t, err)
+	p := &Parser{}
+	assert.Nil(t, p.Register("0200", &rulesData{}))
+	msg, err := p.Parse(raw)
+	assert.Nil(t, err)
+
+	h := Chain(func(ctx context.Context, msg *Message) (*Message, error) {
+		return msg, nil
+	}, ValidateMiddleware(Rules{Requires(23, 2)}))
+	_, err = h(context.Background(), msg)
+	assert.EqualError(t, err, "field 23 requires 2")
+}
+
+func TestMuxConcurrent(t *testing.T) {
+	mux := NewMux()
+	h := func(ctx context.Context, msg *Message) (*Message, error) {
+		return msg, nil
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			mux.Handle("0200", h)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		mux.Serve(context.Background(), NewMessage("0200", &rulesData{}))
+	}
+	<-done
+	_, err := mux.Serve(context.Background(), NewMessage("0200", &rulesData{}))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,43 @@
+package iso8583
+
+import (
+	"fmt"
+)
+
+// transactionTypes is the table read by LookupTransactionType
+var transactionTypes = map[string]string{
+	"0100": "Authorization Request",
+	"0110": "Authorization Response",
+	"0120": "Authorization Advice",
+	"0130": "Authorization Advice Response",
+	"0200": "Authorization Request",
+	"0210": "Authorization Response",
+	"0220": "Financial Transaction Advice",
+	"0230": "Financial Transaction Advice Response",
+	"0400": "Reversal Request",
+	"0410": "Reversal Response",
+	"0420": "Reversal Advice",
+	"0430": "Reversal Advice Response",
+	"0500": "Reconciliation Request",
+	"0510": "Reconciliation Response",
+	"0800": "Network Management Request",
+	"0810": "Network Management Response",
+	"0820": "Network Management Advice",
+	"0830": "Network Management Advice Response",
+}
+
+// LookupTransactionType returns the name of mti, such as "Authorization
+// Request" for 0200
+func LookupTransactionType(mti string) (name string, ok bool) {
+	name, ok = transactionTypes[mti]
+	return name, ok
+}
+
+// TransactionType returns the name of the MTI of the message, as given by
+// LookupTransactionType, or "Unknown (XXXX)" for unknown MTIs
+func (m *Message) TransactionType() string {
+	if name, ok := LookupTransactionType(m.Mti); ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown (%s)", m.Mti)
+}
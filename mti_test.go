@@ -0,0 +1,41 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionType(t *testing.T) {
+	expected := map[string]string{
+		"0100": "Authorization Request",
+		"0110": "Authorization Response",
+		"0200": "Authorization Request",
+		"0210": "Authorization Response",
+		"0220": "Financial Transaction Advice",
+		"0230": "Financial Transaction Advice Response",
+		"0400": "Reversal Request",
+		"0410": "Reversal Response",
+		"0420": "Reversal Advice",
+		"0430": "Reversal Advice Response",
+		"0800": "Network Management Request",
+		"0810": "Network Management Response",
+		"0820": "Network Management Advice",
+		"0830": "Network Management Advice Response",
+	}
+	for mti, name := range expected {
+		assert.Equal(t, name, NewMessage(mti, &TestISO{}).TransactionType(), mti)
+	}
+
+	assert.Equal(t, "Unknown (0999)", NewMessage("0999", &TestISO{}).TransactionType())
+	assert.Equal(t, "Unknown ()", NewMessage("", &TestISO{}).TransactionType())
+}
+
+func TestLookupTransactionType(t *testing.T) {
+	name, ok := LookupTransactionType("0500")
+	assert.True(t, ok)
+	assert.Equal(t, "Reconciliation Request", name)
+
+	_, ok = LookupTransactionType("0999")
+	assert.False(t, ok)
+}
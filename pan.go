@@ -0,0 +1,73 @@
+package iso8583
+
+import (
+	"strconv"
+)
+
+// cardSchemes are the issuer identification number ranges used by Scheme,
+// checked in order. The ranges are compared on the leading digits of the
+// PAN, as many as the bounds have.
+var cardSchemes = []struct {
+	name     string
+	from, to string
+}{
+	{"Amex", "34", "34"},
+	{"Amex", "37", "37"},
+	{"Diners", "300", "305"},
+	{"Diners", "36", "36"},
+	{"Diners", "38", "39"},
+	{"JCB", "3528", "3589"},
+	{"Visa", "4", "4"},
+	{"Mastercard", "51", "55"},
+	{"Mastercard", "2221", "2720"},
+	{"Discover", "6011", "6011"},
+	{"Discover", "644", "649"},
+	{"Discover", "65", "65"},
+	{"UnionPay", "62", "62"},
+}
+
+// IsPAN reports whether the value looks like a card number: 12 to 19
+// digits with a valid Luhn check digit
+func (n *Numeric) IsPAN() bool {
+	if l := len(n.Digits()); l < 12 || l > 19 {
+		return false
+	}
+	return n.ValidateCheckDigit("luhn") == nil
+}
+
+// BIN returns the bank identification number, the first 6 digits of the
+// value, or an empty string for shorter values
+func (n *Numeric) BIN() string {
+	digits := n.Digits()
+	if len(digits) < 6 {
+		return ""
+	}
+	return digits[:6]
+}
+
+// IIN returns the issuer identification number, the same as BIN
+func (n *Numeric) IIN() string {
+	return n.BIN()
+}
+
+// Scheme guesses the card scheme of a PAN from its leading digits: "Visa",
+// "Mastercard", "Amex", "Discover", "JCB", "Diners" or "UnionPay". It
+// returns "Unknown" for other values.
+func (n *Numeric) Scheme() string {
+	digits := n.Digits()
+	for _, s := range cardSchemes {
+		if len(digits) < len(s.from) {
+			continue
+		}
+		prefix, err := strconv.Atoi(digits[:len(s.from)])
+		if err != nil {
+			continue
+		}
+		from, _ := strconv.Atoi(s.from)
+		to, _ := strconv.Atoi(s.to)
+		if prefix >= from && prefix <= to {
+			return s.name
+		}
+	}
+	return "Unknown"
+}
@@ -0,0 +1,49 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericIsPAN(t *testing.T) {
+	assert.True(t, NewNumeric("4111111111111111").IsPAN())
+	assert.True(t, NewNumeric("378282246310005").IsPAN())
+	assert.False(t, NewNumeric("4111111111111112").IsPAN())
+	// 11 digits with a valid check digit
+	assert.False(t, NewNumeric("41111111112").IsPAN())
+	// 20 digits with a valid check digit
+	assert.False(t, NewNumeric("41111111111111111115").IsPAN())
+	assert.False(t, NewNumeric("4111a11111111111").IsPAN())
+
+	var nilNumeric *Numeric
+	assert.False(t, nilNumeric.IsPAN())
+}
+
+func TestNumericBIN(t *testing.T) {
+	n := NewNumeric("4111111111111111")
+	assert.Equal(t, "411111", n.BIN())
+	assert.Equal(t, "411111", n.IIN())
+	assert.Equal(t, "", NewNumeric("41111").BIN())
+}
+
+func TestNumericScheme(t *testing.T) {
+	for pan, scheme := range map[string]string{
+		"4111111111111111": "Visa",
+		"5555555555554444": "Mastercard",
+		"2223003122003222": "Mastercard",
+		"378282246310005":  "Amex",
+		"341111111111111":  "Amex",
+		"6011111111111117": "Discover",
+		"6511111111111111": "Discover",
+		"3530111333300000": "JCB",
+		"30569309025904":   "Diners",
+		"6212345678901232": "UnionPay",
+		"9111111111111111": "Unknown",
+		"2720999999999999": "Mastercard",
+		"2721000000000000": "Unknown",
+		"":                 "Unknown",
+	} {
+		assert.Equal(t, scheme, NewNumeric(pan).Scheme(), pan)
+	}
+}
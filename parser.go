@@ -10,6 +10,39 @@ import (
 type Parser struct {
 	messages  map[string]reflect.Type
 	MtiEncode int
+
+	// RetainRaw makes parsed messages keep a copy of their raw bytes
+	RetainRaw bool
+
+	// OnField is set as OnField of parsed messages
+	OnField func(n int, f Iso8583Type, raw []byte)
+
+	// StrictCardData makes Parse fail when CheckCardDataConsistency of the
+	// parsed message has findings
+	StrictCardData bool
+
+	// StrictMCC makes Parse fail when field 18 of the parsed message is not
+	// a valid merchant category code, see ValidateMCC
+	StrictMCC bool
+
+	// Presence is set as Presence of parsed messages
+	Presence PresenceScheme
+
+	// Encoder is set as Encoder of parsed messages
+	Encoder MessageEncoder
+
+	// SecondaryBitmap is set as SecondaryBitmap of parsed messages, for
+	// encoding them again. Decoding accepts any secondary bitmap.
+	SecondaryBitmap SecondaryBitmapMode
+
+	// Faults, when set, injects faults into the bytes passed to Parse. It is
+	// for tests only, see FaultInjector.
+	Faults FaultInjector
+
+	// MaxMessageSize, when not zero, is the largest size in bytes Parse
+	// accepts. The framed readers check it against the length head before
+	// reading the message, and otherwise accept frames of up to 1 MiB.
+	MaxMessageSize int
 }
 
 // Register MTI
@@ -25,6 +58,9 @@ func (p *Parser) Register(mti string, tpl interface{}) (err error) {
 	}
 	v := reflect.ValueOf(tpl)
 	// TODO do more check
+	if err := checkTemplateWireBytes(reflect.Indirect(v).Type()); err != nil {
+		return err
+	}
 	if p.messages == nil {
 		p.messages = make(map[string]reflect.Type)
 	}
@@ -33,6 +69,10 @@ func (p *Parser) Register(mti string, tpl interface{}) (err error) {
 	return nil
 }
 
+func init() {
+	registerFeature("bcd_mti")
+}
+
 func decodeMti(raw []byte, encode int) (string, error) {
 	mtiLen := 4
 	if encode == BCD {
@@ -55,28 +95,85 @@ func decodeMti(raw []byte, encode int) (string, error) {
 }
 
 //Parse MTI
-func (p *Parser) Parse(raw []byte) (ret *Message, err error) {
+func (p *Parser) Parse(raw []byte) (*Message, error) {
+	msg, _, err := p.ParseWithExtents(raw)
+	return msg, err
+}
+
+// ParseWithExtents is Parse which also returns where the parts of the
+// message were found in raw, see Message.LoadWithExtents
+func (p *Parser) ParseWithExtents(raw []byte) (*Message, Extents, error) {
+	msg, _, ext, err := p.parse(raw)
+	if p.Encoder != nil {
+		ext.parts = nil
+	}
+	return msg, ext, err
+}
+
+// parse parses raw and returns the message, the number of bytes read and
+// the extents of its parts in the standard layout, see Message.load
+func (p *Parser) parse(raw []byte) (ret *Message, n int, ext Extents, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.New("Critical error:" + fmt.Sprint(r))
 			ret = nil
 		}
+		err = decodeError(err)
+		if err != nil {
+			n, ext = 0, Extents{}
+		}
 	}()
 
-	mti, err := decodeMti(raw, p.MtiEncode)
+	given := raw
+	if p.Faults != nil {
+		raw = p.Faults.BeforeDecode(raw)
+	}
+	if p.MaxMessageSize > 0 && len(raw) > p.MaxMessageSize {
+		return nil, 0, Extents{}, &ErrMessageTooLarge{Size: len(raw), Limit: p.MaxMessageSize}
+	}
+	layout := raw
+	if p.Encoder != nil {
+		if layout, err = standardLayout(p.Encoder, raw, p.MtiEncode); err != nil {
+			return nil, 0, Extents{}, err
+		}
+	}
+	mti, err := decodeMti(layout, p.MtiEncode)
 	if err != nil {
-		return nil, err
+		return nil, 0, Extents{}, err
 	}
 
 	tp, ok := p.messages[mti]
 	if !ok {
-		return nil, errors.New("no template registered for MTI: " + mti)
+		return nil, 0, Extents{}, errors.New("no template registered for MTI: " + mti)
 	}
 	tpl := reflect.New(tp)
 	initStruct(tp, tpl)
 	msg := NewMessage(mti, tpl.Interface())
 	msg.MtiEncode = p.MtiEncode
-	return msg, msg.Load(raw)
+	msg.OnField = p.OnField
+	msg.Presence = p.Presence
+	msg.Encoder = p.Encoder
+	msg.SecondaryBitmap = p.SecondaryBitmap
+	n, ext, err = msg.load(raw)
+	// Raw holds the bytes given, not the ones changed by Faults
+	msg.RetainRaw = p.RetainRaw
+	if p.RetainRaw {
+		msg.raw = copyBytes(given)
+	}
+	if err != nil {
+		return msg, 0, Extents{}, err
+	}
+	if p.StrictCardData {
+		if found := msg.CheckCardDataConsistency(); found != nil {
+			return msg, 0, Extents{}, cardDataError(found)
+		}
+	}
+	if p.StrictMCC {
+		if err := checkMCC(msg); err != nil {
+			return msg, 0, Extents{}, err
+		}
+	}
+	return msg, n, ext, nil
 }
 
 func initStruct(tp reflect.Type, val reflect.Value) {
@@ -0,0 +1,133 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+func init() {
+	registerFeature("presence")
+}
+
+// PresenceScheme encodes which fields a message holds, for formats which
+// replace the bitmap. EncodePresence is given the present fields in
+// ascending order; DecodePresence returns them in the order their data
+// follows, and the number of bytes read.
+type PresenceScheme interface {
+	EncodePresence(fields []int) []byte
+	DecodePresence(raw []byte) ([]int, int, error)
+}
+
+// BitmapPresence is the standard primary bitmap, with a secondary bitmap
+// when fields above 64 are present
+type BitmapPresence struct{}
+
+// EncodePresence implements PresenceScheme
+func (BitmapPresence) EncodePresence(fields []int) []byte {
+	var b Bitmap
+	size := 8
+	for _, n := range fields {
+		if n > 64 {
+			b.Set(1)
+			size = 16
+		}
+		b.Set(n)
+	}
+	return b[:size]
+}
+
+// DecodePresence implements PresenceScheme
+func (BitmapPresence) DecodePresence(raw []byte) ([]int, int, error) {
+	var b Bitmap
+	if len(raw) < 8 {
+		return nil, 0, errors.New("bitmap too short")
+	}
+	size := 8
+	if raw[0]&0x80 != 0 {
+		size = 16
+	}
+	if len(raw) < size {
+		return nil, 0, errors.New("secondary bitmap too short")
+	}
+	copy(b[:], raw[:size])
+	return b.Fields(), size, nil
+}
+
+// FixedFields returns the scheme of formats without bitmap, where the listed
+// fields are always present in this order
+func FixedFields(fields []int) PresenceScheme {
+	return fixedFields(append([]int(nil), fields...))
+}
+
+type fixedFields []int
+
+func (f fixedFields) EncodePresence(fields []int) []byte {
+	return nil
+}
+
+func (f fixedFields) DecodePresence(raw []byte) ([]int, int, error) {
+	return append([]int(nil), f...), 0, nil
+}
+
+// CountPrefixed returns the scheme of formats where a byte holding the
+// number of fields is followed by the field numbers, one byte each
+func CountPrefixed() PresenceScheme {
+	return countPrefixed{}
+}
+
+type countPrefixed struct{}
+
+func (countPrefixed) EncodePresence(fields []int) []byte {
+	ret := make([]byte, 0, len(fields)+1)
+	ret = append(ret, byte(len(fields)))
+	for _, n := range fields {
+		ret = append(ret, byte(n))
+	}
+	return ret
+}
+
+func (countPrefixed) DecodePresence(raw []byte) ([]int, int, error) {
+	if len(raw) < 1 {
+		return nil, 0, errors.New("field count missing")
+	}
+	count := int(raw[0])
+	if len(raw) < count+1 {
+		return nil, 0, fmt.Errorf("%d field numbers expected, %d found", count, len(raw)-1)
+	}
+	ret := make([]int, count)
+	for i := range ret {
+		ret[i] = int(raw[i+1])
+	}
+	return ret, count + 1, nil
+}
+
+// encodePresence returns the encoded presence of fields and the order their
+// data follows in. It checks that scheme can represent fields, so a message
+// with fields a fixed layout or a count byte cannot carry fails to encode.
+func encodePresence(scheme PresenceScheme, fields []int) ([]byte, []int, error) {
+	b := scheme.EncodePresence(fields)
+	order, _, err := scheme.DecodePresence(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !sameFields(order, fields) {
+		return nil, nil, fmt.Errorf("fields %v cannot be encoded, expected %v", fields, order)
+	}
+	return b, order, nil
+}
+
+func sameFields(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[int]bool, len(a))
+	for _, n := range a {
+		set[n] = true
+	}
+	for _, n := range b {
+		if !set[n] {
+			return false
+		}
+	}
+	return true
+}
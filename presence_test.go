@@ -0,0 +1,78 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type presenceData struct {
+	F3  *Numeric      `field:"3" length:"6" encode:"ascii"`
+	F11 *Numeric      `field:"11" length:"6" encode:"ascii"`
+	F41 *Alphanumeric `field:"41" length:"8"`
+	F70 *Numeric      `field:"70" length:"3" encode:"ascii"`
+}
+
+func presenceRoundTrip(t *testing.T, scheme PresenceScheme, data *presenceData) []byte {
+	msg := NewMessage("0800", data)
+	msg.Presence = scheme
+	b, err := msg.Bytes()
+	assert.NoError(t, err)
+
+	p := &Parser{Presence: scheme}
+	assert.NoError(t, p.Register("0800", &presenceData{}))
+	parsed, err := p.Parse(b)
+	assert.NoError(t, err)
+	again, err := parsed.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, b, again)
+	assert.Equal(t, data.F11, parsed.Data.(*presenceData).F11)
+	return b
+}
+
+func TestBitmapPresence(t *testing.T) {
+	data := &presenceData{
+		F3:  NewNumeric("000000"),
+		F11: NewNumeric("000001"),
+		F70: NewNumeric("301"),
+	}
+	b := presenceRoundTrip(t, BitmapPresence{}, data)
+
+	std := NewMessage("0800", data)
+	std.SecondBitmap = true
+	want, err := std.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, want, b)
+
+	data.F70 = nil
+	b = presenceRoundTrip(t, BitmapPresence{}, data)
+	assert.Equal(t, "0800"+"\x20\x20\x00\x00\x00\x00\x00\x00"+"000000000001", string(b))
+}
+
+func TestFixedFieldsPresence(t *testing.T) {
+	scheme := FixedFields([]int{11, 3, 41})
+	data := &presenceData{
+		F3:  NewNumeric("000000"),
+		F11: NewNumeric("000001"),
+		F41: NewAlphanumeric("TERM0001"),
+	}
+	b := presenceRoundTrip(t, scheme, data)
+	assert.Equal(t, "0800000001000000TERM0001", string(b))
+
+	data.F41 = nil
+	_, err := (&Message{Mti: "0800", Data: data, Presence: scheme}).Bytes()
+	assert.EqualError(t, err, "fields [3 11] cannot be encoded, expected [11 3 41]")
+}
+
+func TestCountPrefixedPresence(t *testing.T) {
+	data := &presenceData{
+		F11: NewNumeric("000001"),
+		F41: NewAlphanumeric("TERM0001"),
+		F70: NewNumeric("301"),
+	}
+	b := presenceRoundTrip(t, CountPrefixed(), data)
+	assert.Equal(t, "0800\x03\x0b\x29\x46000001TERM0001301", string(b))
+
+	_, err := (&Parser{Presence: CountPrefixed()}).Parse([]byte("0800\x03\x0b"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package iso8583
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// frameHeadLen is the size of the big-endian length head framing messages
+// written by Replay and read by ReadAndRecord
+const frameHeadLen = 2
+
+func writeFrame(w io.Writer, b []byte) error {
+	if len(b) > 0xFFFF {
+		return fmt.Errorf("message too long for frame: %d bytes", len(b))
+	}
+	frame := make([]byte, frameHeadLen, frameHeadLen+len(b))
+	binary.BigEndian.PutUint16(frame, uint16(len(b)))
+	_, err := w.Write(append(frame, b...))
+	return err
+}
+
+// defaultMaxFrameSize is the largest frame readFrame accepts when the
+// parser sets no MaxMessageSize
+const defaultMaxFrameSize = 1 << 20
+
+// readFrame reads a message framed with a big-endian length head of
+// headLen (1 to 4) bytes. The head is not trusted: a frame larger than
+// limit, or defaultMaxFrameSize when limit is not positive, fails with
+// ErrMessageTooLarge before its bytes are read.
+func readFrame(r io.Reader, headLen, limit int) ([]byte, error) {
+	if headLen < 1 || headLen > 4 {
+		return nil, fmt.Errorf("invalid frame head length %d", headLen)
+	}
+	if limit <= 0 {
+		limit = defaultMaxFrameSize
+	}
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(r, head[4-headLen:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(head)
+	if uint64(size) > uint64(limit) {
+		return nil, &ErrMessageTooLarge{Size: int(size), Limit: limit}
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Replay packs the message and writes it to w, usually a net.Conn, framed
+// with a 2 byte big-endian length head
+func (m *Message) Replay(w io.Writer) error {
+	b, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, b)
+}
+
+// RecordedSession is a sequence of messages which can be played back to a
+// connection, for test harnesses
+type RecordedSession struct {
+	Messages []*Message
+}
+
+// Add appends a copy of msg to the session
+func (s *RecordedSession) Add(msg *Message) {
+	s.Messages = append(s.Messages, msg.Clone())
+}
+
+// Play writes all messages of the session to w in order, with the framing
+// of Replay
+func (s *RecordedSession) Play(w io.Writer) error {
+	for i, msg := range s.Messages {
+		if err := msg.Replay(w); err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ReadAndRecord reads n framed messages from r, usually a net.Conn, parses
+// them with p and records them in a new session
+func ReadAndRecord(r io.Reader, n int, p *Parser) (*RecordedSession, error) {
+	if p == nil {
+		return nil, errors.New("parser is required")
+	}
+	s := &RecordedSession{}
+	for i := 0; i < n; i++ {
+		b, err := readFrame(r, frameHeadLen, p.MaxMessageSize)
+		if err != nil {
+			return s, fmt.Errorf("message %d: %w", i, err)
+		}
+		msg, err := p.Parse(b)
+		if err != nil {
+			return s, fmt.Errorf("message %d: %w", i, err)
+		}
+		s.Messages = append(s.Messages, msg)
+	}
+	return s, nil
+}
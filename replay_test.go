@@ -0,0 +1,85 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	iso := NewMessage("0100", &TestISO{
+		F2: NewLlnumeric("4276555555555555"),
+		F3: NewNumeric("000000"),
+	})
+	packed, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, iso.Replay(&buf))
+	assert.Equal(t, []byte{0, byte(len(packed))}, buf.Bytes()[:2])
+	assert.Equal(t, packed, buf.Bytes()[2:])
+
+	iso.Mti = "bad"
+	assert.EqualError(t, iso.Replay(&buf), "MTI is invalid")
+}
+
+func TestRecordedSession(t *testing.T) {
+	session := &RecordedSession{}
+	data := &TestISO{
+		F2: NewLlnumeric("4276555555555555"),
+		F3: NewNumeric("000000"),
+	}
+	session.Add(NewMessage("0100", data))
+	data.F3.Value = "300000"
+	session.Add(NewMessage("0100", data))
+	session.Add(NewMessage("0800", &TestISO{F7: NewNumeric("0701111844")}))
+
+	parser := &Parser{}
+	assert.Nil(t, parser.Register("0100", &TestISO{}))
+	assert.Nil(t, parser.Register("0800", &TestISO{}))
+
+	client, server := net.Pipe()
+	defer server.Close()
+	go func() {
+		defer client.Close()
+		assert.Nil(t, session.Play(client))
+	}()
+
+	recorded, err := ReadAndRecord(server, 3, parser)
+	assert.Nil(t, err)
+	assert.Len(t, recorded.Messages, 3)
+	for i, msg := range session.Messages {
+		expected, err := msg.Bytes()
+		assert.Nil(t, err)
+		res, err := recorded.Messages[i].Bytes()
+		assert.Nil(t, err)
+		assert.Equal(t, expected, res)
+	}
+	assert.Equal(t, "000000", recorded.Messages[0].Data.(*TestISO).F3.Value)
+	assert.Equal(t, "300000", recorded.Messages[1].Data.(*TestISO).F3.Value)
+	assert.Equal(t, "0800", recorded.Messages[2].Mti)
+
+	_, err = ReadAndRecord(bytes.NewReader([]byte{0, 10, 1}), 1, parser)
+	assert.EqualError(t, err, "message 0: unexpected EOF")
+}
+
+func TestReadAndRecordErrors(t *testing.T) {
+	parser := &Parser{}
+	assert.Nil(t, parser.Register("0100", &TestISO{}))
+	b, err := NewMessage("0100", &TestISO{F2: NewLlnumeric("4276555555555555")}).Bytes()
+	assert.Nil(t, err)
+
+	// a well framed message whose field 2 is cut short
+	corrupt := b[:len(b)-3]
+	_, err = ReadAndRecord(bytes.NewReader(append([]byte{0, byte(len(corrupt))}, corrupt...)), 1, parser)
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr), "%v", err)
+
+	parser.MaxMessageSize = 8
+	_, err = ReadAndRecord(bytes.NewReader(append([]byte{0, byte(len(b))}, b...)), 1, parser)
+	var tooLarge *ErrMessageTooLarge
+	assert.True(t, errors.As(err, &tooLarge), "%v", err)
+}
@@ -0,0 +1,140 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DeclineCategory groups response codes by how the caller should react
+type DeclineCategory int
+
+const (
+	// CategoryUnknown is used for response codes unknown to
+	// LookupResponseCode
+	CategoryUnknown DeclineCategory = iota
+	// CategorySoftDecline is a decline that may succeed on retry or with more data
+	CategorySoftDecline
+	// CategoryHardDecline is a final decline for the card or transaction
+	CategoryHardDecline
+	// CategoryFormatError is a decline caused by an invalid request
+	CategoryFormatError
+	// CategorySystemError is a decline caused by the issuer or the network
+	CategorySystemError
+)
+
+func (c DeclineCategory) String() string {
+	switch c {
+	case CategorySoftDecline:
+		return "soft decline"
+	case CategoryHardDecline:
+		return "hard decline"
+	case CategoryFormatError:
+		return "format error"
+	case CategorySystemError:
+		return "system error"
+	}
+	return "unknown"
+}
+
+// ResponseCodeInfo describes a field 39 response code
+type ResponseCodeInfo struct {
+	Description string
+	Approved    bool
+	Retryable   bool
+	Category    DeclineCategory
+}
+
+// responseCodesMu guards responseCodes against RegisterResponseCode
+var responseCodesMu sync.RWMutex
+
+// responseCodes is the table read by LookupResponseCode
+var responseCodes = map[string]ResponseCodeInfo{
+	"00": {Description: "Approved", Approved: true},
+	"01": {Description: "Refer to card issuer", Category: CategorySoftDecline},
+	"03": {Description: "Invalid merchant", Category: CategoryHardDecline},
+	"04": {Description: "Pick up card", Category: CategoryHardDecline},
+	"05": {Description: "Do not honor", Category: CategoryHardDecline},
+	"08": {Description: "Honor with identification", Approved: true},
+	"10": {Description: "Approved for partial amount", Approved: true},
+	"11": {Description: "Approved (VIP)", Approved: true},
+	"12": {Description: "Invalid transaction", Category: CategoryFormatError},
+	"13": {Description: "Invalid amount", Category: CategoryFormatError},
+	"14": {Description: "Invalid card number", Category: CategoryHardDecline},
+	"30": {Description: "Format error", Category: CategoryFormatError},
+	"41": {Description: "Lost card, pick up", Category: CategoryHardDecline},
+	"43": {Description: "Stolen card, pick up", Category: CategoryHardDecline},
+	"51": {Description: "Insufficient funds", Category: CategorySoftDecline},
+	"54": {Description: "Expired card", Category: CategoryHardDecline},
+	"55": {Description: "Incorrect PIN", Category: CategorySoftDecline},
+	"57": {Description: "Transaction not permitted to cardholder", Category: CategoryHardDecline},
+	"61": {Description: "Exceeds withdrawal amount limit", Category: CategorySoftDecline},
+	"65": {Description: "Exceeds withdrawal frequency limit", Category: CategorySoftDecline},
+	"75": {Description: "Allowable number of PIN tries exceeded", Category: CategoryHardDecline},
+	"91": {Description: "Issuer or switch inoperative", Retryable: true, Category: CategorySystemError},
+	"92": {Description: "Routing error", Category: CategorySystemError},
+	"94": {Description: "Duplicate transmission", Category: CategoryFormatError},
+	"96": {Description: "System malfunction", Retryable: true, Category: CategorySystemError},
+}
+
+// LookupResponseCode returns the description of a field 39 response code
+func LookupResponseCode(code string) (info ResponseCodeInfo, ok bool) {
+	responseCodesMu.RLock()
+	defer responseCodesMu.RUnlock()
+	info, ok = responseCodes[code]
+	return info, ok
+}
+
+// RegisterResponseCode adds a private response code, or overrides how a
+// standard one is described and classified, for LookupResponseCode and
+// ResponseError. It is safe for concurrent use; it is usually called from
+// an init function for the codes of a host.
+func RegisterResponseCode(code string, info ResponseCodeInfo) {
+	responseCodesMu.Lock()
+	defer responseCodesMu.Unlock()
+	responseCodes[code] = info
+}
+
+// DeclineError is returned by ResponseError for response codes which are
+// not approvals
+type DeclineError struct {
+	Code        string
+	Description string
+	Retryable   bool
+	Category    DeclineCategory
+}
+
+func (e *DeclineError) Error() string {
+	return fmt.Sprintf("declined with response code %s: %s (%s)", e.Code, e.Description, e.Category)
+}
+
+// ResponseError checks the response code (field 39) of msg. It returns nil
+// for approvals and a *DeclineError for any other code, described by
+// LookupResponseCode.
+func ResponseError(msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	f, ok := parseFields(msg.Data)[39]
+	if !ok || f.Field.IsEmpty() {
+		return errors.New("field 39 not set")
+	}
+	code := strings.TrimSpace(fieldString(f.Field))
+	info, ok := LookupResponseCode(code)
+	if !ok {
+		return &DeclineError{Code: code, Description: "Unknown response code", Category: CategoryUnknown}
+	}
+	if info.Approved {
+		return nil
+	}
+	return &DeclineError{
+		Code:        code,
+		Description: info.Description,
+		Retryable:   info.Retryable,
+		Category:    info.Category,
+	}
+}
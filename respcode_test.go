@@ -0,0 +1,74 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseError(t *testing.T) {
+	data := &TestISO{F39: NewAlphanumeric("00")}
+	msg := NewMessage("0210", data)
+
+	assert.Nil(t, ResponseError(msg))
+
+	data.F39.Value = "91"
+	err := ResponseError(msg)
+	var decline *DeclineError
+	assert.True(t, errors.As(err, &decline))
+	assert.Equal(t, "91", decline.Code)
+	assert.True(t, decline.Retryable)
+	assert.Equal(t, CategorySystemError, decline.Category)
+
+	data.F39.Value = "51"
+	err = ResponseError(msg)
+	assert.EqualError(t, err, "declined with response code 51: Insufficient funds (soft decline)")
+	assert.False(t, err.(*DeclineError).Retryable)
+
+	data.F39.Value = "05"
+	err = ResponseError(msg)
+	assert.Equal(t, CategoryHardDecline, err.(*DeclineError).Category)
+
+	data.F39.Value = "Q1"
+	err = ResponseError(msg)
+	assert.Equal(t, &DeclineError{Code: "Q1", Description: "Unknown response code"}, err)
+
+	data.F39 = nil
+	assert.EqualError(t, ResponseError(msg), "field 39 not set")
+}
+
+func TestLookupResponseCode(t *testing.T) {
+	info, ok := LookupResponseCode("96")
+	assert.True(t, ok)
+	assert.Equal(t, ResponseCodeInfo{Description: "System malfunction", Retryable: true, Category: CategorySystemError}, info)
+
+	info, ok = LookupResponseCode("00")
+	assert.True(t, ok)
+	assert.True(t, info.Approved)
+
+	_, ok = LookupResponseCode("Q1")
+	assert.False(t, ok)
+}
+
+func TestRegisterResponseCode(t *testing.T) {
+	standard, _ := LookupResponseCode("05")
+	t.Cleanup(func() {
+		responseCodesMu.Lock()
+		defer responseCodesMu.Unlock()
+		delete(responseCodes, "Q1")
+		responseCodes["05"] = standard
+	})
+
+	msg := NewMessage("0210", &TestISO{F39: NewAlphanumeric("Q1")})
+	RegisterResponseCode("Q1", ResponseCodeInfo{Description: "Private soft decline", Retryable: true, Category: CategorySoftDecline})
+	err := ResponseError(msg)
+	assert.Equal(t, &DeclineError{Code: "Q1", Description: "Private soft decline", Retryable: true, Category: CategorySoftDecline}, err)
+
+	// a standard code classified otherwise by a host
+	RegisterResponseCode("05", ResponseCodeInfo{Description: "Do not honor", Retryable: true, Category: CategorySoftDecline})
+	msg.Data.(*TestISO).F39.Value = "05"
+	err = ResponseError(msg)
+	assert.Equal(t, CategorySoftDecline, err.(*DeclineError).Category)
+	assert.True(t, err.(*DeclineError).Retryable)
+}
@@ -0,0 +1,100 @@
+package iso8583
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const authCodeChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Sequence is a source of increasing numbers, such as a database sequence
+type Sequence interface {
+	Next() uint64
+}
+
+// counter is the Sequence used when none is given
+type counter struct {
+	n uint64
+}
+
+func (c *counter) Next() uint64 {
+	return atomic.AddUint64(&c.n, 1)
+}
+
+var authCodeSequence = &counter{}
+
+// GenerateAuthCode returns a 6 character authorization identification
+// response (field 38) made of uppercase letters and digits, derived from
+// the next value of src.
+func GenerateAuthCode(src Sequence) string {
+	n := src.Next()
+	code := make([]byte, 6)
+	for i := len(code) - 1; i >= 0; i-- {
+		code[i] = authCodeChars[n%uint64(len(authCodeChars))]
+		n /= uint64(len(authCodeChars))
+	}
+	return string(code)
+}
+
+// SettlementDate returns the settlement date (field 15) as MMDD for a
+// transaction at now. Transactions at or after cutoverHour in loc settle
+// on the next day.
+func SettlementDate(now time.Time, cutoverHour int, loc *time.Location) string {
+	t := now.In(loc)
+	if t.Hour() >= cutoverHour {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t.Format("0102")
+}
+
+type approveConfig struct {
+	authCodes   Sequence
+	now         func() time.Time
+	cutoverHour int
+	loc         *time.Location
+}
+
+// ApproveOption configures ApproveResponse
+type ApproveOption func(*approveConfig)
+
+// WithAuthCodeSequence sets the sequence auth codes are generated from
+func WithAuthCodeSequence(src Sequence) ApproveOption {
+	return func(c *approveConfig) {
+		c.authCodes = src
+	}
+}
+
+// WithCutover sets the settlement cutover hour and its location. Without it
+// the settlement date is the current UTC date.
+func WithCutover(hour int, loc *time.Location) ApproveOption {
+	return func(c *approveConfig) {
+		c.cutoverHour = hour
+		c.loc = loc
+	}
+}
+
+// WithClock sets the function returning the current time
+func WithClock(now func() time.Time) ApproveOption {
+	return func(c *approveConfig) {
+		c.now = now
+	}
+}
+
+// ApproveResponse creates an approved 0210 answer to req: field 39 is "00",
+// field 38 a generated auth code and field 15 the settlement date. Fields are
+// echoed from the request as by NewAuthResponse.
+func ApproveResponse(req *Message, opts ...ApproveOption) *Message {
+	c := &approveConfig{
+		authCodes:   authCodeSequence,
+		now:         time.Now,
+		cutoverHour: 24,
+		loc:         time.UTC,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	resp := NewAuthResponse(req, "00", GenerateAuthCode(c.authCodes))
+	resp.Data.(*AuthResponse).SettlementDate = NewNumeric(SettlementDate(c.now(), c.cutoverHour, c.loc))
+	return resp
+}
@@ -0,0 +1,71 @@
+package iso8583
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedSequence []uint64
+
+func (s *fixedSequence) Next() uint64 {
+	n := (*s)[0]
+	*s = (*s)[1:]
+	return n
+}
+
+func TestGenerateAuthCode(t *testing.T) {
+	seq := &fixedSequence{0, 35, 36, 2176782335, 2176782336}
+	assert.Equal(t, "000000", GenerateAuthCode(seq))
+	assert.Equal(t, "00000Z", GenerateAuthCode(seq))
+	assert.Equal(t, "000010", GenerateAuthCode(seq))
+	assert.Equal(t, "ZZZZZZ", GenerateAuthCode(seq))
+	assert.Equal(t, "000000", GenerateAuthCode(seq))
+
+	re := regexp.MustCompile(`^[0-9A-Z]{6}$`)
+	for i := 0; i < 100; i++ {
+		assert.Regexp(t, re, GenerateAuthCode(authCodeSequence))
+	}
+}
+
+func TestSettlementDate(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*60*60)
+
+	before := time.Date(2020, 12, 31, 22, 59, 59, 0, loc)
+	assert.Equal(t, "1231", SettlementDate(before, 23, loc))
+	at := time.Date(2020, 12, 31, 23, 0, 0, 0, loc)
+	assert.Equal(t, "0101", SettlementDate(at, 23, loc))
+
+	// the cutover applies in the given location
+	utc := time.Date(2020, 12, 31, 16, 0, 0, 0, time.UTC)
+	assert.Equal(t, "0101", SettlementDate(utc, 23, loc))
+	assert.Equal(t, "1231", SettlementDate(utc, 23, time.UTC))
+
+	assert.Equal(t, "1231", SettlementDate(at, 24, loc))
+}
+
+func TestApproveResponse(t *testing.T) {
+	req := NewMessage("0200", &TestISO{
+		F7:  NewNumeric("0701111844"),
+		F11: NewNumeric("000123"),
+	})
+
+	now := func() time.Time { return time.Date(2020, 7, 1, 23, 30, 0, 0, time.UTC) }
+	resp := ApproveResponse(req,
+		WithAuthCodeSequence(&fixedSequence{36}),
+		WithCutover(23, time.UTC),
+		WithClock(now))
+	assert.Equal(t, "0210", resp.Mti)
+
+	data := resp.Data.(*AuthResponse)
+	assert.Equal(t, "00", data.ResponseCode.Value)
+	assert.Equal(t, "000010", data.ApprovalCode.Value)
+	assert.Equal(t, "0702", data.SettlementDate.Value)
+	assert.Equal(t, "0701111844", data.TransmissionDateTime.Value)
+	assert.Equal(t, "000123", data.STAN.Value)
+
+	resp = ApproveResponse(req, WithClock(now))
+	assert.Equal(t, "0701", resp.Data.(*AuthResponse).SettlementDate.Value)
+}
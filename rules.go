@@ -0,0 +1,164 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	registerFeature("field_rules")
+}
+
+// RuleEnforcement selects what Bytes does with the Rules of a message
+type RuleEnforcement int
+
+const (
+	// RulesNotEnforced leaves the rules to Validate
+	RulesNotEnforced RuleEnforcement = iota
+	// RulesReject makes Bytes fail when a rule is broken
+	RulesReject
+	// RulesDrop makes Bytes leave out the fields of the second group of a
+	// broken Exclusive rule. Other broken rules still fail.
+	RulesDrop
+)
+
+// FieldGroup is a set of fields taken together by a Rule. It is present
+// when any of its fields is.
+type FieldGroup []int
+
+// Group returns the fields as a FieldGroup
+func Group(fields ...int) FieldGroup {
+	return FieldGroup(fields)
+}
+
+func (g FieldGroup) String() string {
+	s := make([]string, len(g))
+	for i, n := range g {
+		s[i] = fmt.Sprint(n)
+	}
+	return strings.Join(s, "+")
+}
+
+func (g FieldGroup) present(set map[int]bool) bool {
+	for _, n := range g {
+		if set[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule is a constraint between the fields of a message
+type Rule struct {
+	exclusive bool
+	first     FieldGroup
+	second    FieldGroup
+}
+
+// Rules is a list of rules, checked together
+type Rules []Rule
+
+// Exclusive returns a rule allowing either group, never both, as with
+// Exclusive(Group(35), Group(2, 14)) for track 2 or PAN and expiry date.
+// The first group has priority: RulesDrop leaves out the second.
+func Exclusive(first, second FieldGroup) Rule {
+	return Rule{exclusive: true, first: first, second: second}
+}
+
+// Requires returns a rule requiring all of the fields in required when
+// field is present, as with Requires(23, 2) for the PAN sequence number
+func Requires(field int, required ...int) Rule {
+	return Rule{first: Group(field), second: Group(required...)}
+}
+
+// check returns the error of a broken rule, or nil
+func (r Rule) check(set map[int]bool) error {
+	if !r.first.present(set) {
+		return nil
+	}
+	if r.exclusive {
+		if r.second.present(set) {
+			return fmt.Errorf("fields %s and %s are exclusive", r.first, r.second)
+		}
+		return nil
+	}
+	var missing []string
+	for _, n := range r.second {
+		if !set[n] {
+			missing = append(missing, fmt.Sprint(n))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("field %s requires %s", r.first, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Validate checks the message against its Rules and returns an error
+// listing every broken rule
+func (m *Message) Validate() error {
+	set, err := m.presentFields()
+	if err != nil {
+		return err
+	}
+	_, err = m.Rules.check(set, false)
+	return err
+}
+
+func (m *Message) presentFields() (map[int]bool, error) {
+	fields, err := m.fields()
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[int]bool, len(fields))
+	for n, info := range fields {
+		if !info.Field.IsEmpty() {
+			set[n] = true
+		}
+	}
+	return set, nil
+}
+
+// check returns the fields of set to drop when drop is set, and an error
+// listing the rules which are broken once every drop is made
+func (rs Rules) check(set map[int]bool, drop bool) (map[int]bool, error) {
+	var dropped map[int]bool
+	if drop {
+		for _, r := range rs {
+			if !r.exclusive || !r.first.present(set) || !r.second.present(set) {
+				continue
+			}
+			if dropped == nil {
+				dropped = make(map[int]bool)
+			}
+			for _, n := range r.second {
+				dropped[n] = true
+				delete(set, n)
+			}
+		}
+	}
+	var broken []string
+	for _, r := range rs {
+		if err := r.check(set); err != nil {
+			broken = append(broken, err.Error())
+		}
+	}
+	if len(broken) > 0 {
+		return nil, errors.New(strings.Join(broken, "; "))
+	}
+	return dropped, nil
+}
+
+// ruleDrops applies the Rules as selected by EnforceRules before encoding
+// and returns the fields to leave out
+func (m *Message) ruleDrops() (map[int]bool, error) {
+	if m.EnforceRules == RulesNotEnforced || len(m.Rules) == 0 {
+		return nil, nil
+	}
+	set, err := m.presentFields()
+	if err != nil {
+		return nil, err
+	}
+	return m.Rules.check(set, m.EnforceRules == RulesDrop)
+}
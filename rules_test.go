@@ -0,0 +1,95 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rulesData struct {
+	F2  *Llnumeric `field:"2" length:"19"`
+	F3  *Numeric   `field:"3" length:"6"`
+	F14 *Numeric   `field:"14" length:"4"`
+	F23 *Numeric   `field:"23" length:"3"`
+	F35 *Llvar     `field:"35" length:"37"`
+}
+
+func newRulesMessage() (*Message, *rulesData) {
+	data := &rulesData{
+		F2:  NewLlnumeric("4111111111111111"),
+		F3:  NewNumeric("000000"),
+		F14: NewNumeric("2512"),
+		F35: NewLlvar([]byte("4111111111111111=2512")),
+	}
+	msg := NewMessage("0200", data)
+	msg.Rules = []Rule{
+		Exclusive(Group(35), Group(2, 14)),
+		Requires(23, 2),
+	}
+	return msg, data
+}
+
+func TestMessageValidate(t *testing.T) {
+	msg, data := newRulesMessage()
+	assert.EqualError(t, msg.Validate(), "fields 35 and 2+14 are exclusive")
+
+	data.F2 = nil
+	data.F14 = nil
+	data.F23 = NewNumeric("001")
+	assert.EqualError(t, msg.Validate(), "field 23 requires 2")
+
+	data.F35 = nil
+	data.F2 = NewLlnumeric("4111111111111111")
+	assert.Nil(t, msg.Validate())
+
+	// rules are not enforced by default
+	msg, _ = newRulesMessage()
+	_, err := msg.Bytes()
+	assert.Nil(t, err)
+}
+
+func TestMessageEnforceRules(t *testing.T) {
+	msg, data := newRulesMessage()
+	msg.EnforceRules = RulesReject
+	_, err := msg.Bytes()
+	assert.EqualError(t, err, "fields 35 and 2+14 are exclusive")
+
+	msg.EnforceRules = RulesDrop
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	parsed := NewMessage("", &rulesData{
+		F2: &Llnumeric{}, F3: &Numeric{}, F14: &Numeric{}, F35: &Llvar{},
+	})
+	assert.Nil(t, parsed.Load(b))
+	assert.Equal(t, []int{3, 35}, parsed.SortedFieldNumbers())
+	// the data of the message is left as it was
+	assert.NotNil(t, data.F2)
+	assert.NotNil(t, data.F14)
+
+	// only exclusive rules are resolved by dropping, and every rule sees
+	// the fields dropped
+	data.F23 = NewNumeric("001")
+	_, err = msg.Bytes()
+	assert.EqualError(t, err, "field 23 requires 2")
+	data.F35 = nil
+	_, err = msg.Bytes()
+	assert.Nil(t, err)
+}
+
+func TestMessageEnforceRulesOrder(t *testing.T) {
+	msg, data := newRulesMessage()
+	msg.Rules = []Rule{
+		Requires(23, 2),
+		Exclusive(Group(35), Group(2, 14)),
+	}
+	msg.EnforceRules = RulesDrop
+	data.F23 = NewNumeric("001")
+	// rules before an Exclusive rule also see the fields it drops
+	_, err := msg.Bytes()
+	assert.EqualError(t, err, "field 23 requires 2")
+	assert.EqualError(t, msg.Validate(), "fields 35 and 2+14 are exclusive")
+
+	data.F23 = nil
+	_, err = msg.Bytes()
+	assert.Nil(t, err)
+}
@@ -0,0 +1,73 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// MessageSnapshot is an immutable copy of a message taken at a point in
+// time, for audit trails. It has no setters; use Restore to get a mutable
+// message back.
+type MessageSnapshot struct {
+	msg  *Message
+	time time.Time
+}
+
+// Snapshot captures the current state of the message
+func (m *Message) Snapshot() MessageSnapshot {
+	return MessageSnapshot{msg: m.Clone(), time: time.Now()}
+}
+
+// MTI returns the MTI of the snapshot
+func (s MessageSnapshot) MTI() string {
+	return s.msg.Mti
+}
+
+// Time returns the time the snapshot was taken
+func (s MessageSnapshot) Time() time.Time {
+	return s.time
+}
+
+// Fields returns the encoded bytes of every field set in the snapshot. The
+// result is a map, so iterating it in field order is up to the caller.
+func (s MessageSnapshot) Fields() (map[int][]byte, error) {
+	ns, fields, err := s.msg.setFields()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[int][]byte, len(ns))
+	for _, n := range ns {
+		b, err := fields[n].bytes()
+		if err != nil {
+			return nil, err
+		}
+		ret[n] = copyBytes(b)
+	}
+	return ret, nil
+}
+
+// Restore creates a mutable message with the state of the snapshot
+func (s MessageSnapshot) Restore() *Message {
+	return s.msg.Clone()
+}
+
+// MarshalJSON encodes the snapshot with field bytes in hex
+func (s MessageSnapshot) MarshalJSON() ([]byte, error) {
+	ns, fields, err := s.msg.setFields()
+	if err != nil {
+		return nil, err
+	}
+	hexFields, err := fieldsJSON(ns, func(n int) (string, error) {
+		b, err := fields[n].bytes()
+		return hex.EncodeToString(b), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Mti    string          `json:"mti"`
+		Time   time.Time       `json:"time"`
+		Fields json.RawMessage `json:"fields"`
+	}{s.msg.Mti, s.time, hexFields})
+}
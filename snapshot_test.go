@@ -0,0 +1,85 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageSnapshot(t *testing.T) {
+	data := &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F52: NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+	}
+	req := NewMessage("0200", data)
+	orig, err := req.Bytes()
+	assert.Nil(t, err)
+
+	snap := req.Snapshot()
+
+	// processing the message does not change the snapshot
+	data.F3.Value = "300000"
+	data.F52.Value[0] = 9
+	req.Mti = "0210"
+
+	assert.Equal(t, "0200", snap.MTI())
+	assert.False(t, snap.Time().IsZero())
+
+	fields, err := snap.Fields()
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]byte{
+		2:  []byte("164276555555555555"),
+		3:  []byte("000000"),
+		52: {1, 2, 3, 4, 5, 6, 7, 8},
+	}, fields)
+
+	restored := snap.Restore()
+	res, err := restored.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, orig, res)
+
+	// the restored message is mutable without touching the snapshot
+	restored.Data.(*TestISO).F3.Value = "310000"
+	fields, err = snap.Fields()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("000000"), fields[3])
+
+	b, err := json.Marshal(snap)
+	assert.Nil(t, err)
+	var decoded struct {
+		Mti    string            `json:"mti"`
+		Fields map[string]string `json:"fields"`
+	}
+	assert.Nil(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "0200", decoded.Mti)
+	assert.Equal(t, map[string]string{
+		"2":  "313634323736353535353535353535353535",
+		"3":  "303030303030",
+		"52": "0102030405060708",
+	}, decoded.Fields)
+}
+
+func TestSnapshotMarshalJSONDeterministic(t *testing.T) {
+	snap := NewMessage("0200", &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F3:  NewNumeric("000000"),
+		F11: NewNumeric("000001"),
+		F41: NewAlphanumeric("TERM0001"),
+	}).Snapshot()
+
+	first, err := json.Marshal(snap)
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
+		b, err := json.Marshal(snap)
+		assert.Nil(t, err)
+		assert.Equal(t, first, b)
+	}
+
+	s := string(first)
+	assert.True(t, strings.Index(s, `"2":`) < strings.Index(s, `"3":`))
+	assert.True(t, strings.Index(s, `"3":`) < strings.Index(s, `"11":`))
+	assert.True(t, strings.Index(s, `"11":`) < strings.Index(s, `"41":`))
+}
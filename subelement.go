@@ -0,0 +1,79 @@
+package iso8583
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// SubElementSpec holds validation rules for the sub-elements of a field
+type SubElementSpec struct {
+	// MaxLength is the longest value allowed per tag. Tags which are not
+	// listed are not limited.
+	MaxLength map[string]int
+}
+
+// A LlvarBuilder assembles sub-elements into the value of a variable length
+// field such as field 48. Every sub-element is written as its tag, the
+// length of its value and the value. The length has as many ASCII digits as
+// the tag has characters, so tags of 2 characters take a 2 digit length and
+// tags of 3 characters a 3 digit length.
+type LlvarBuilder struct {
+	elements bytes.Buffer
+	spec     *SubElementSpec
+	err      error
+}
+
+// NewLlvarBuilder create new LlvarBuilder
+func NewLlvarBuilder() *LlvarBuilder {
+	return &LlvarBuilder{}
+}
+
+// WithSubElementSpec sets the rules sub-elements are validated with
+func (b *LlvarBuilder) WithSubElementSpec(spec *SubElementSpec) *LlvarBuilder {
+	b.spec = spec
+	return b
+}
+
+// AddSubElement appends a sub-element. An invalid sub-element is reported
+// by Build.
+func (b *LlvarBuilder) AddSubElement(tag string, value []byte) *LlvarBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(tag) != 2 && len(tag) != 3 {
+		b.err = fmt.Errorf("sub-element tag %q must be 2 or 3 characters", tag)
+		return b
+	}
+	if b.spec != nil {
+		if max, ok := b.spec.MaxLength[tag]; ok && len(value) > max {
+			b.err = fmt.Errorf("sub-element %s: %w", tag, &ErrValueTooLong{"SubElement", max, len(value)})
+			return b
+		}
+	}
+	if len(value) > maxForDigits(len(tag)) {
+		b.err = fmt.Errorf("sub-element %s: %w", tag, &ErrValueTooLong{"SubElement", maxForDigits(len(tag)), len(value)})
+		return b
+	}
+	b.elements.WriteString(tag)
+	b.elements.WriteString(fmt.Sprintf("%0*d", len(tag), len(value)))
+	b.elements.Write(value)
+	return b
+}
+
+// Build returns the sub-elements as an Lllvar field, or the first error of
+// AddSubElement
+func (b *LlvarBuilder) Build() (*Lllvar, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return NewLllvar(copyBytes(b.elements.Bytes())), nil
+}
+
+// maxForDigits is the largest length n decimal digits can hold
+func maxForDigits(n int) int {
+	max := 1
+	for i := 0; i < n; i++ {
+		max *= 10
+	}
+	return max - 1
+}
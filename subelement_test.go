@@ -0,0 +1,38 @@
+package iso8583
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLlvarBuilder(t *testing.T) {
+	f48, err := NewLlvarBuilder().
+		AddSubElement("10", []byte("A")).
+		AddSubElement("42", []byte("210")).
+		AddSubElement("ABC", []byte("hello")).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("1001A4203210ABC005hello"), f48.Value)
+
+	b, err := f48.Bytes(ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, "0231001A4203210ABC005hello", string(b))
+
+	_, err = NewLlvarBuilder().
+		AddSubElement("1", []byte("A")).
+		AddSubElement("42", []byte("210")).
+		Build()
+	assert.EqualError(t, err, `sub-element tag "1" must be 2 or 3 characters`)
+
+	_, err = NewLlvarBuilder().AddSubElement("10", bytes.Repeat([]byte("A"), 100)).Build()
+	assert.EqualError(t, err, "sub-element 10: length of value is longer than definition; type=SubElement, def_len=99, len=100")
+
+	spec := &SubElementSpec{MaxLength: map[string]int{"42": 2}}
+	_, err = NewLlvarBuilder().WithSubElementSpec(spec).
+		AddSubElement("10", []byte("ABC")).
+		AddSubElement("42", []byte("210")).
+		Build()
+	assert.EqualError(t, err, "sub-element 42: length of value is longer than definition; type=SubElement, def_len=2, len=3")
+}
@@ -0,0 +1,135 @@
+package iso8583
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+func init() {
+	registerFeature("tagged_fields")
+}
+
+// A TaggedLLLField is an Lllvar field holding a list of tagged elements, as
+// used by AS2805 in fields 47 and 48. Each element is a 3 character tag, a 3
+// digit length and the value. The order of the elements is kept.
+type TaggedLLLField struct {
+	elements []taggedElement
+}
+
+type taggedElement struct {
+	tag   string
+	value string
+}
+
+// NewTaggedLLLField create new TaggedLLLField field
+func NewTaggedLLLField() *TaggedLLLField {
+	return &TaggedLLLField{}
+}
+
+// Get returns the value of the element with the tag
+func (t *TaggedLLLField) Get(tag string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	for _, e := range t.elements {
+		if e.tag == tag {
+			return e.value, true
+		}
+	}
+	return "", false
+}
+
+// Set sets the value of the element with the tag. An existing element keeps
+// its position, a new one is appended.
+func (t *TaggedLLLField) Set(tag, value string) error {
+	if t == nil {
+		return &ErrNilField{"TaggedLLLField"}
+	}
+	if len(tag) != 3 {
+		return fmt.Errorf("tag %q must be 3 characters", tag)
+	}
+	if len(value) > 999 {
+		return fmt.Errorf("tag %s: value longer than 999", tag)
+	}
+	for i := range t.elements {
+		if t.elements[i].tag == tag {
+			t.elements[i].value = value
+			return nil
+		}
+	}
+	t.elements = append(t.elements, taggedElement{tag, value})
+	return nil
+}
+
+// Tags returns the tags of the elements in order
+func (t *TaggedLLLField) Tags() []string {
+	if t == nil {
+		return nil
+	}
+	tags := make([]string, len(t.elements))
+	for i, e := range t.elements {
+		tags[i] = e.tag
+	}
+	return tags
+}
+
+// IsEmpty check TaggedLLLField field for empty value
+func (t *TaggedLLLField) IsEmpty() bool {
+	return t == nil || len(t.elements) == 0
+}
+
+func (t *TaggedLLLField) payload() []byte {
+	var b bytes.Buffer
+	for _, e := range t.elements {
+		b.WriteString(e.tag)
+		b.WriteString(fmt.Sprintf("%03d", len(e.value)))
+		b.WriteString(e.value)
+	}
+	return b.Bytes()
+}
+
+// Bytes encode TaggedLLLField field to bytes
+func (t *TaggedLLLField) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if t == nil {
+		return nil, &ErrNilField{"TaggedLLLField"}
+	}
+	return NewLllvar(t.payload()).Bytes(encoder, lenEncoder, length)
+}
+
+// Load decode TaggedLLLField field from bytes
+func (t *TaggedLLLField) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	if t == nil {
+		return 0, &ErrNilField{"TaggedLLLField"}
+	}
+	l := &Lllvar{}
+	read, err := l.Load(raw, encoder, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+
+	var elements []taggedElement
+	data := l.Value
+	for len(data) > 0 {
+		if len(data) < 6 {
+			return 0, &ErrBadRaw{"TaggedLLLField", 6, len(data)}
+		}
+		tag := string(data[:3])
+		n, err := strconv.Atoi(string(data[3:6]))
+		if err != nil || n < 0 || len(data) < 6+n {
+			return 0, fmt.Errorf("tag %s: %w", tag, &ErrBadRaw{"TaggedLLLField", 6 + n, len(data)})
+		}
+		elements = append(elements, taggedElement{tag, string(data[6 : 6+n])})
+		data = data[6+n:]
+	}
+	t.elements = elements
+	return read, nil
+}
+
+// Copy returns a deep copy of the TaggedLLLField field
+func (t *TaggedLLLField) Copy() Iso8583Type {
+	if t == nil {
+		return (*TaggedLLLField)(nil)
+	}
+	return &TaggedLLLField{append([]taggedElement(nil), t.elements...)}
+}
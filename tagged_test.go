@@ -0,0 +1,63 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaggedLLLField(t *testing.T) {
+	f := NewTaggedLLLField()
+	assert.True(t, f.IsEmpty())
+	assert.Nil(t, f.Set("BAT", "01"))
+	assert.Nil(t, f.Set("TCC", "07"))
+	assert.Nil(t, f.Set("CTI", "R"))
+	assert.Nil(t, f.Set("BAT", "02"))
+	assert.EqualError(t, f.Set("BA", "01"), `tag "BA" must be 3 characters`)
+
+	assert.Equal(t, []string{"BAT", "TCC", "CTI"}, f.Tags())
+	v, ok := f.Get("BAT")
+	assert.True(t, ok)
+	assert.Equal(t, "02", v)
+	_, ok = f.Get("XXX")
+	assert.False(t, ok)
+
+	b, err := f.Bytes(ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, "023BAT00202TCC00207CTI001R", string(b))
+
+	loaded := &TaggedLLLField{}
+	read, err := loaded.Load(b, ASCII, ASCII, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), read)
+	assert.Equal(t, f.Tags(), loaded.Tags())
+	v, _ = loaded.Get("CTI")
+	assert.Equal(t, "R", v)
+
+	_, err = loaded.Load([]byte("010BAT005AB"), ASCII, ASCII, 999)
+	assert.EqualError(t, err, "bad raw data")
+	_, err = loaded.Load([]byte("008BAT00501"), ASCII, ASCII, 999)
+	assert.EqualError(t, err, "tag BAT: bad raw data")
+}
+
+func TestMessageTaggedLLLField(t *testing.T) {
+	type data struct {
+		F47 *TaggedLLLField `field:"47" length:"999"`
+	}
+	f47 := NewTaggedLLLField()
+	assert.Nil(t, f47.Set("TCC", "07"))
+	b, err := NewMessage("0200", &data{F47: f47}).Bytes()
+	assert.Nil(t, err)
+
+	parsed := NewMessage("", &data{F47: NewTaggedLLLField()})
+	assert.Nil(t, parsed.Load(b))
+	v, ok := parsed.Data.(*data).F47.Get("TCC")
+	assert.True(t, ok)
+	assert.Equal(t, "07", v)
+
+	// clones do not share elements
+	c := parsed.Clone()
+	assert.Nil(t, c.Data.(*data).F47.Set("TCC", "08"))
+	v, _ = parsed.Data.(*data).F47.Get("TCC")
+	assert.Equal(t, "07", v)
+}
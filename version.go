@@ -0,0 +1,55 @@
+package iso8583
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// version of the package, reported by Version
+const version = "0.2.0"
+
+var (
+	featuresMu sync.RWMutex
+	features   = make(map[string]bool)
+)
+
+// Version returns the version of the package
+func Version() string {
+	return version
+}
+
+// Features returns the behavioral capabilities compiled into the package,
+// such as "secondary_bitmap" or "bcd_mti". The returned map is a copy.
+func Features() map[string]bool {
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+
+	ret := make(map[string]bool, len(features))
+	for name, enabled := range features {
+		ret[name] = enabled
+	}
+	return ret
+}
+
+// versionHeader describes the version and the enabled features of the
+// package in one line, for reports such as Message.Debug
+func versionHeader() string {
+	var names []string
+	for name, enabled := range Features() {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return "iso8583 " + Version() + " features: " + strings.Join(names, ",")
+}
+
+// registerFeature adds a capability to the Features registry. It is called
+// from init functions next to the code implementing the feature.
+func registerFeature(name string) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+
+	features[name] = true
+}
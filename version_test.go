@@ -0,0 +1,83 @@
+package iso8583
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	assert.NotEmpty(t, Version())
+}
+
+func TestFeatures(t *testing.T) {
+	features := Features()
+	for _, name := range []string{
+		"bcd_mti",
+		"binary_length_head",
+		"content_encoding",
+		"continuation",
+		"ebcdic",
+		"even_digits",
+		"extents",
+		"fault_injection",
+		"field_rules",
+		"llalpha",
+		"llbinary",
+		"lllalpha",
+		"lllbinary",
+		"llllnumeric",
+		"llllvar",
+		"max_message_size",
+		"mcc",
+		"message_encoder",
+		"middleware",
+		"pan_masking",
+		"parallel_batch_decode",
+		"presence",
+		"raw_retention",
+		"rbcd",
+		"secondary_bitmap",
+		"tagged_fields",
+		"typed_errors",
+		"zone_bcd",
+	} {
+		assert.True(t, features[name], name)
+		delete(features, name)
+	}
+	// every registered feature is listed above
+	assert.Empty(t, features)
+
+	// the result is a copy
+	Features()["bcd_mti"] = false
+	assert.True(t, Features()["bcd_mti"])
+}
+
+func TestVersionHeader(t *testing.T) {
+	defer func() {
+		featuresMu.Lock()
+		delete(features, "test_feature")
+		featuresMu.Unlock()
+	}()
+	header := versionHeader()
+	assert.True(t, strings.HasPrefix(header, "iso8583 "+Version()+" features: bcd_mti,binary_length_head,"), header)
+	assert.NotContains(t, header, "test_feature")
+
+	registerFeature("test_feature")
+	assert.True(t, strings.HasSuffix(versionHeader(), ",tagged_fields,test_feature,typed_errors,zone_bcd"))
+
+	first := strings.SplitN(newMaskMessage().Debug(), "\n", 2)[0]
+	assert.Equal(t, versionHeader(), first)
+}
+
+func TestRegisterFeature(t *testing.T) {
+	defer func() {
+		featuresMu.Lock()
+		delete(features, "test_feature")
+		featuresMu.Unlock()
+	}()
+	assert.False(t, Features()["test_feature"])
+	registerFeature("test_feature")
+	assert.True(t, Features()["test_feature"])
+}
@@ -0,0 +1,63 @@
+package iso8583
+
+import (
+	"fmt"
+)
+
+func init() {
+	registerFeature("zone_bcd")
+}
+
+// zone BCD codes of letters: the zone is the high nibble, the position of the
+// letter in its row the low nibble
+var zoneLetters = [26]byte{
+	0xC1, 0xC2, 0xC3, 0xC4, 0xC5, 0xC6, 0xC7, 0xC8, 0xC9, // A-I
+	0xD1, 0xD2, 0xD3, 0xD4, 0xD5, 0xD6, 0xD7, 0xD8, 0xD9, // J-R
+	0xE2, 0xE3, 0xE4, 0xE5, 0xE6, 0xE7, 0xE8, 0xE9, // S-Z
+}
+
+const zoneSpace = 0x40
+
+func zoneEncode(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, c := range data {
+		switch {
+		case c >= '0' && c <= '9':
+			out[i] = 0xF0 | (c - '0')
+		case c >= 'A' && c <= 'Z':
+			out[i] = zoneLetters[c-'A']
+		case c == ' ':
+			out[i] = zoneSpace
+		default:
+			return nil, fmt.Errorf("character %q at %d can not be zone BCD encoded", c, i)
+		}
+	}
+	return out, nil
+}
+
+func zoneDecode(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		c, ok := zoneChar(b)
+		if !ok {
+			return nil, fmt.Errorf("invalid zone BCD byte 0x%02x at %d", b, i)
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+func zoneChar(b byte) (byte, bool) {
+	if b >= 0xF0 && b <= 0xF9 {
+		return '0' + b - 0xF0, true
+	}
+	if b == zoneSpace {
+		return ' ', true
+	}
+	for i, l := range zoneLetters {
+		if l == b {
+			return 'A' + byte(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,49 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlphanumericZoneBCD(t *testing.T) {
+	a := NewAlphanumeric("ABC")
+	b, err := a.Bytes(ZoneBCD, 0, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xC1, 0xC2, 0xC3}, b)
+
+	a = NewAlphanumeric("123")
+	b, err = a.Bytes(ZoneBCD, 0, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x40, 0x40, 0xF1, 0xF2, 0xF3}, b)
+
+	a = NewAlphanumeric("JSZ09")
+	b, err = a.Bytes(ZoneBCD, 0, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xD1, 0xE2, 0xE9, 0xF0, 0xF9}, b)
+
+	loaded := &Alphanumeric{}
+	read, err := loaded.Load(b, ZoneBCD, 0, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, read)
+	assert.Equal(t, "JSZ09", loaded.Value)
+
+	_, err = NewAlphanumeric("ab").Bytes(ZoneBCD, 0, 2)
+	assert.EqualError(t, err, "character 'a' at 0 can not be zone BCD encoded")
+
+	_, err = loaded.Load([]byte{0xC1, 0xE1}, ZoneBCD, 0, 2)
+	assert.EqualError(t, err, "invalid zone BCD byte 0xe1 at 1")
+}
+
+func TestMessageZoneBCD(t *testing.T) {
+	type data struct {
+		F41 *Alphanumeric `field:"41" length:"8" encode:"zbcd"`
+	}
+	msg := NewMessage("0800", &data{F41: NewAlphanumeric("TERM01")})
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+
+	parsed := NewMessage("", &data{F41: NewAlphanumeric("")})
+	assert.Nil(t, parsed.Load(b))
+	assert.Equal(t, "  TERM01", parsed.Data.(*data).F41.Value)
+}